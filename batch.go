@@ -0,0 +1,288 @@
+package sortedmap
+
+import "golang.org/x/exp/constraints"
+
+type batchOp int
+
+const (
+	batchInsert batchOp = iota
+	batchDelete
+	batchSet
+	batchDeleteRange
+)
+
+type setBatchOp[K constraints.Ordered] struct {
+	op       batchOp
+	value    K
+	endValue K
+}
+
+type SetBatch[K constraints.Ordered] struct {
+	ops []setBatchOp[K]
+}
+
+func NewSetBatch[K constraints.Ordered]() *SetBatch[K] {
+	return &SetBatch[K]{}
+}
+
+func (b *SetBatch[K]) Len() int {
+	return len(b.ops)
+}
+
+func (b *SetBatch[K]) Reset() {
+	b.ops = b.ops[:0]
+}
+
+func (b *SetBatch[K]) Insert(value K) *SetBatch[K] {
+	b.ops = append(b.ops, setBatchOp[K]{op: batchInsert, value: value})
+	return b
+}
+
+func (b *SetBatch[K]) Delete(value K) *SetBatch[K] {
+	b.ops = append(b.ops, setBatchOp[K]{op: batchDelete, value: value})
+	return b
+}
+
+func (b *SetBatch[K]) DeleteRange(startValue K, endValue K) *SetBatch[K] {
+	b.ops = append(b.ops, setBatchOp[K]{op: batchDeleteRange, value: startValue, endValue: endValue})
+	return b
+}
+
+func (s *NoLockSortedSet[K]) Apply(b *SetBatch[K]) {
+	for _, op := range b.ops {
+		switch op.op {
+		case batchInsert:
+			s.Insert(op.value)
+		case batchDelete:
+			s.Delete(op.value)
+		case batchDeleteRange:
+			s.deleteRange(op.value, op.endValue)
+		}
+	}
+}
+
+func (s *SortedSet[K]) Apply(b *SetBatch[K]) {
+	s.m.Lock()
+	s.s.Apply(b)
+	s.m.Unlock()
+}
+
+// Update runs fn on a copy of the set and replaces the contents with it only
+// when fn returns nil. Readers see either all or none of the changes, and
+// the stats only count the changes that were kept. Every call copies the
+// whole set, so prefer Apply for small changes that cannot fail.
+func (s *SortedSet[K]) Update(fn func(tx *NoLockSortedSet[K]) error) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	tx := s.s.clone(0)
+	if s.s.stats != nil {
+		tx.stats = &statsCounters{}
+	}
+	var events []ChangeEvent[K, struct{}]
+	if s.s.hook != nil {
+		tx.hook = func(e ChangeEvent[K, struct{}]) {
+			events = append(events, e)
+		}
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	s.s.stats.add(tx.stats.snapshot())
+	s.s.values = tx.values
+	s.s.filter = tx.filter
+	for _, e := range events {
+		s.s.hook(e)
+	}
+	return nil
+}
+
+type mapBatchOp[K constraints.Ordered, V any] struct {
+	op     batchOp
+	key    K
+	endKey K
+	value  V
+}
+
+type MapBatch[K constraints.Ordered, V any] struct {
+	ops []mapBatchOp[K, V]
+}
+
+func NewMapBatch[K constraints.Ordered, V any]() *MapBatch[K, V] {
+	return &MapBatch[K, V]{}
+}
+
+func (b *MapBatch[K, V]) Len() int {
+	return len(b.ops)
+}
+
+func (b *MapBatch[K, V]) Reset() {
+	b.ops = b.ops[:0]
+}
+
+func (b *MapBatch[K, V]) Insert(key K, value V) *MapBatch[K, V] {
+	b.ops = append(b.ops, mapBatchOp[K, V]{op: batchInsert, key: key, value: value})
+	return b
+}
+
+func (b *MapBatch[K, V]) Delete(key K) *MapBatch[K, V] {
+	b.ops = append(b.ops, mapBatchOp[K, V]{op: batchDelete, key: key})
+	return b
+}
+
+// Set inserts the entry or overwrites the value of an existing key.
+func (b *MapBatch[K, V]) Set(key K, value V) *MapBatch[K, V] {
+	b.ops = append(b.ops, mapBatchOp[K, V]{op: batchSet, key: key, value: value})
+	return b
+}
+
+func (b *MapBatch[K, V]) DeleteRange(startKey K, endKey K) *MapBatch[K, V] {
+	b.ops = append(b.ops, mapBatchOp[K, V]{op: batchDeleteRange, key: startKey, endKey: endKey})
+	return b
+}
+
+func (s *NoLockSortedMap[K, V]) Apply(b *MapBatch[K, V]) {
+	for _, op := range b.ops {
+		switch op.op {
+		case batchInsert:
+			s.Insert(op.key, op.value)
+		case batchDelete:
+			s.Delete(op.key)
+		case batchSet:
+			s.set(op.key, op.value)
+		case batchDeleteRange:
+			s.deleteRange(op.key, op.endKey)
+		}
+	}
+}
+
+func (s *SortedMap[K, V]) Apply(b *MapBatch[K, V]) {
+	s.m.Lock()
+	s.s.Apply(b)
+	s.m.Unlock()
+}
+
+// Update runs fn on a copy of the map and replaces the contents with it only
+// when fn returns nil. Readers see either all or none of the changes, and
+// the stats only count the changes that were kept. Every call copies the
+// whole map, so prefer Apply for small changes that cannot fail.
+func (s *SortedMap[K, V]) Update(fn func(tx *NoLockSortedMap[K, V]) error) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	tx := s.s.clone(0)
+	if s.s.stats != nil {
+		tx.stats = &statsCounters{}
+	}
+	var events []ChangeEvent[K, V]
+	if s.s.hook != nil {
+		tx.hook = func(e ChangeEvent[K, V]) {
+			events = append(events, e)
+		}
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	s.s.stats.add(tx.stats.snapshot())
+	s.s.keys = tx.keys
+	s.s.values = tx.values
+	for _, e := range events {
+		s.s.hook(e)
+	}
+	return nil
+}
+
+type mapCalcBatchOp[K constraints.Ordered, V any] struct {
+	op     batchOp
+	key    K
+	endKey K
+	value  V
+}
+
+type MapCalcBatch[K constraints.Ordered, V any] struct {
+	ops []mapCalcBatchOp[K, V]
+}
+
+func NewMapCalcBatch[K constraints.Ordered, V any]() *MapCalcBatch[K, V] {
+	return &MapCalcBatch[K, V]{}
+}
+
+func (b *MapCalcBatch[K, V]) Len() int {
+	return len(b.ops)
+}
+
+func (b *MapCalcBatch[K, V]) Reset() {
+	b.ops = b.ops[:0]
+}
+
+func (b *MapCalcBatch[K, V]) Insert(value V) *MapCalcBatch[K, V] {
+	b.ops = append(b.ops, mapCalcBatchOp[K, V]{op: batchInsert, value: value})
+	return b
+}
+
+func (b *MapCalcBatch[K, V]) Delete(value V) *MapCalcBatch[K, V] {
+	b.ops = append(b.ops, mapCalcBatchOp[K, V]{op: batchDelete, value: value})
+	return b
+}
+
+// Set inserts the value or overwrites the value having the same key.
+func (b *MapCalcBatch[K, V]) Set(value V) *MapCalcBatch[K, V] {
+	b.ops = append(b.ops, mapCalcBatchOp[K, V]{op: batchSet, value: value})
+	return b
+}
+
+func (b *MapCalcBatch[K, V]) DeleteRange(startKey K, endKey K) *MapCalcBatch[K, V] {
+	b.ops = append(b.ops, mapCalcBatchOp[K, V]{op: batchDeleteRange, key: startKey, endKey: endKey})
+	return b
+}
+
+func (s *NoLockSortedMapCalc[K, V]) Apply(b *MapCalcBatch[K, V]) {
+	for _, op := range b.ops {
+		switch op.op {
+		case batchInsert:
+			s.Insert(op.value)
+		case batchDelete:
+			s.Delete(op.value)
+		case batchSet:
+			s.set(op.value)
+		case batchDeleteRange:
+			s.deleteRange(op.key, op.endKey)
+		}
+	}
+}
+
+func (s *SortedMapCalc[K, V]) Apply(b *MapCalcBatch[K, V]) {
+	s.m.Lock()
+	s.s.Apply(b)
+	s.m.Unlock()
+}
+
+// Update runs fn on a copy of the map and replaces the contents with it only
+// when fn returns nil. Readers see either all or none of the changes, and
+// the stats only count the changes that were kept. Every call copies the
+// whole map, so prefer Apply for small changes that cannot fail.
+func (s *SortedMapCalc[K, V]) Update(fn func(tx *NoLockSortedMapCalc[K, V]) error) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	tx := s.s.clone(0)
+	if s.s.stats != nil {
+		tx.stats = &statsCounters{}
+	}
+	var events []ChangeEvent[K, V]
+	if s.s.hook != nil {
+		tx.hook = func(e ChangeEvent[K, V]) {
+			events = append(events, e)
+		}
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	s.s.stats.add(tx.stats.snapshot())
+	s.s.keys = tx.keys
+	s.s.values = tx.values
+	for _, e := range events {
+		s.s.hook(e)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package sortedmap_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sapphi-red/sortedmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortedSet_Apply(t *testing.T) {
+	t.Parallel()
+
+	set := sortedmap.NewSortedSet[int](5)
+	set.InsertAll([]int{1, 2, 3, 4, 5})
+
+	batch := sortedmap.NewSetBatch[int]().Delete(1).DeleteRange(3, 4).Insert(9)
+	assert.Equal(t, 3, batch.Len())
+	set.Apply(batch)
+	assert.Equal(t, []int{2, 5, 9}, set.GetGreaterOrEqual(0))
+
+	batch.Reset()
+	assert.Equal(t, 0, batch.Len())
+}
+
+func TestSortedSet_Update(t *testing.T) {
+	t.Parallel()
+
+	set := sortedmap.NewSortedSet[int](5)
+	set.InsertAll([]int{1, 2})
+	set.EnableStats()
+
+	errAbort := errors.New("abort")
+	err := set.Update(func(tx *sortedmap.NoLockSortedSet[int]) error {
+		tx.Delete(1)
+		tx.Insert(3)
+		return errAbort
+	})
+	assert.ErrorIs(t, err, errAbort)
+	assert.Equal(t, []int{1, 2}, set.GetGreaterOrEqual(0))
+	assert.Equal(t, uint64(0), set.Stats().Inserts)
+	assert.Equal(t, uint64(0), set.Stats().Deletes)
+
+	err = set.Update(func(tx *sortedmap.NoLockSortedSet[int]) error {
+		tx.Delete(1)
+		tx.Insert(3)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, set.GetGreaterOrEqual(0))
+	assert.Equal(t, uint64(1), set.Stats().Inserts)
+	assert.Equal(t, uint64(1), set.Stats().Deletes)
+}
+
+func TestSortedMap_Apply(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMap[int, string](5)
+	m.InsertAll([]int{1, 2, 3, 4}, []string{"1", "2", "3", "4"})
+
+	m.Apply(sortedmap.NewMapBatch[int, string]().
+		Insert(1, "ignored").
+		Set(2, "two").
+		Set(7, "seven").
+		Delete(1).
+		DeleteRange(3, 5))
+	assert.Equal(t, []string{"two", "seven"}, m.GetGreaterOrEqual(0))
+}
+
+func TestSortedMap_Update(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMap[int, string](5)
+	m.Insert(1, "1")
+	m.EnableStats()
+
+	errAbort := errors.New("abort")
+	err := m.Update(func(tx *sortedmap.NoLockSortedMap[int, string]) error {
+		tx.Clear()
+		return errAbort
+	})
+	assert.ErrorIs(t, err, errAbort)
+	assert.Equal(t, []string{"1"}, m.GetGreaterOrEqual(0))
+	assert.Equal(t, uint64(0), m.Stats().Deletes)
+
+	err = m.Update(func(tx *sortedmap.NoLockSortedMap[int, string]) error {
+		tx.Delete(1)
+		tx.Insert(2, "2")
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2"}, m.GetGreaterOrEqual(0))
+	assert.Equal(t, uint64(1), m.Stats().Deletes)
+	assert.Equal(t, uint64(1), m.Stats().Inserts)
+}
+
+func TestSortedMapCalc_Apply(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMapCalc(5, safeAtoi)
+	m.InsertAll([]string{"1", "2", "3"})
+
+	m.Apply(sortedmap.NewMapCalcBatch[int, string]().
+		Set("02").
+		Delete("1").
+		DeleteRange(3, 3).
+		Insert("4"))
+	assert.Equal(t, []string{"02", "4"}, m.GetGreaterOrEqual(0))
+}
+
+func TestSortedMapCalc_Update(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMapCalc(5, safeAtoi)
+	m.Insert("1")
+
+	errAbort := errors.New("abort")
+	err := m.Update(func(tx *sortedmap.NoLockSortedMapCalc[int, string]) error {
+		tx.Insert("2")
+		return errAbort
+	})
+	assert.ErrorIs(t, err, errAbort)
+	assert.Equal(t, false, m.Contains(2))
+
+	err = m.Update(func(tx *sortedmap.NoLockSortedMapCalc[int, string]) error {
+		tx.Insert("2")
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, true, m.Contains(2))
+}
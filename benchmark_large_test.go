@@ -0,0 +1,18 @@
+package sortedmap
+
+import "math/rand"
+
+// largeSizes are the sizes at which the Large benchmarks compare the slice
+// backend with the chunked and frozen ones and the trees.
+var largeSizes = []int{1000, 10000, 100000, 1000000}
+
+// largeKeys returns odd keys to insert into a container holding the even
+// keys in [0, 2*size).
+func largeKeys(size int) []int {
+	rnd := rand.New(rand.NewSource(1))
+	keys := make([]int, 1024)
+	for i := range keys {
+		keys[i] = rnd.Intn(size)*2 + 1
+	}
+	return keys
+}
@@ -1,6 +1,7 @@
 package sortedmap
 
 import (
+	"math/rand"
 	"strconv"
 	"testing"
 
@@ -66,6 +67,21 @@ func BenchmarkMapCalc_Contains(b *testing.B) {
 	}
 }
 
+func BenchmarkFrozenMap_Contains(b *testing.B) {
+	m := NewNoLockSortedMap[int, string](MapContainsSize)
+	for i := 0; i < m.Capacity(); i++ {
+		m.Insert(i*3, strconv.Itoa(i*3))
+	}
+	f := m.Freeze()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sinkMContains = f.Contains(300)
+		sinkMContains = f.Contains(500)
+		sinkMContains = f.Contains(700)
+	}
+}
+
 func BenchmarkIgrmkTreeMapMap_Contains(b *testing.B) {
 	m := igrmkTreeMap.New[int, string]()
 	for i := 0; i < MapContainsSize; i++ {
@@ -94,6 +110,66 @@ func BenchmarkOkAvlTreeMap_Contains(b *testing.B) {
 	}
 }
 
+// BenchmarkLargeMap_Contains looks up random keys so that the searches miss
+// cache once the keys outgrow it.
+func BenchmarkLargeMap_Contains(b *testing.B) {
+	for _, size := range largeSizes {
+		m := NewNoLockSortedMap[int, string](size)
+		for i := 0; i < size; i++ {
+			m.Insert(i*3, "")
+		}
+		f := m.Freeze()
+
+		rnd := rand.New(rand.NewSource(1))
+		keys := make([]int, 4096)
+		for i := range keys {
+			keys[i] = rnd.Intn(size * 3)
+		}
+
+		b.Run("NoLockMap/"+strconv.Itoa(size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				sinkMContains = m.Contains(keys[i%len(keys)])
+			}
+		})
+
+		b.Run("FrozenMap/"+strconv.Itoa(size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				sinkMContains = f.Contains(keys[i%len(keys)])
+			}
+		})
+	}
+}
+
+// BenchmarkSearchStrategy_Contains looks up near-uniform timestamps.
+func BenchmarkSearchStrategy_Contains(b *testing.B) {
+	const size = 1000000
+	rnd := rand.New(rand.NewSource(1))
+	keys := make([]int64, 4096)
+	for i := range keys {
+		keys[i] = 1600000000000 + rnd.Int63n(size*1000)
+	}
+
+	for _, strategy := range []struct {
+		name     string
+		strategy SearchStrategy
+	}{
+		{"Binary", SearchBinary},
+		{"Interpolation", SearchInterpolation},
+		{"Galloping", SearchGalloping},
+	} {
+		m := NewNoLockSortedMapWithOptions[int64, string](WithCapacity(size), WithSearchStrategy(strategy.strategy))
+		for i := 0; i < size; i++ {
+			m.Insert(1600000000000+int64(i)*1000+rnd.Int63n(1000), "")
+		}
+
+		b.Run(strategy.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				sinkMContains = m.Contains(keys[i%len(keys)])
+			}
+		})
+	}
+}
+
 var sinkMContains = false
 
 func safeAtoi(s string) int {
@@ -1,6 +1,7 @@
 package sortedmap
 
 import (
+	"strconv"
 	"testing"
 
 	igrmkTreeMap "github.com/igrmk/treemap/v2"
@@ -102,3 +103,90 @@ func BenchmarkOkAvlTreeMap_Init(b *testing.B) {
 		okAvlTree.NewAVLTreeOrderedKey[int, string]()
 	}
 }
+
+func BenchmarkLargeMap_InsertDelete(b *testing.B) {
+	for _, size := range largeSizes {
+		keys := largeKeys(size)
+
+		b.Run("NoLockMap/"+strconv.Itoa(size), func(b *testing.B) {
+			m := NewNoLockSortedMap[int, string](size + 1)
+			for i := 0; i < size; i++ {
+				m.Insert(i*2, "")
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				key := keys[i%len(keys)]
+				m.Insert(key, "")
+				m.Delete(key)
+			}
+		})
+
+		b.Run("ChunkedMap/"+strconv.Itoa(size), func(b *testing.B) {
+			m := NewChunkedSortedMap[int, string]()
+			for i := 0; i < size; i++ {
+				m.Insert(i*2, "")
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				key := keys[i%len(keys)]
+				m.Insert(key, "")
+				m.Delete(key)
+			}
+		})
+
+		b.Run("IgrmkTreeMap/"+strconv.Itoa(size), func(b *testing.B) {
+			m := igrmkTreeMap.New[int, string]()
+			for i := 0; i < size; i++ {
+				m.Set(i*2, "")
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				key := keys[i%len(keys)]
+				m.Set(key, "")
+				m.Del(key)
+			}
+		})
+
+		b.Run("OkAvlTreeMap/"+strconv.Itoa(size), func(b *testing.B) {
+			m := okAvlTree.NewAVLTreeOrderedKey[int, string]()
+			for i := 0; i < size; i++ {
+				m.Insert(i*2, "")
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				key := keys[i%len(keys)]
+				m.Insert(key, "")
+				m.Erase(key)
+			}
+		})
+	}
+}
+
+// BenchmarkSearchStrategy_InsertWithAfterHint inserts keys that already exist
+// a few entries after the hint, so that only the search is measured.
+func BenchmarkSearchStrategy_InsertWithAfterHint(b *testing.B) {
+	const size = 100000
+	for _, strategy := range []struct {
+		name     string
+		strategy SearchStrategy
+	}{
+		{"Binary", SearchBinary},
+		{"Galloping", SearchGalloping},
+	} {
+		m := NewNoLockSortedMapWithOptions[int, string](WithCapacity(size), WithSearchStrategy(strategy.strategy))
+		for i := 0; i < size; i++ {
+			m.Insert(i*2, "")
+		}
+
+		b.Run(strategy.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				hint := i % (size - 8)
+				m.InsertWithAfterHint(hint*2+8, "", hint)
+			}
+		})
+	}
+}
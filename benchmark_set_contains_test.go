@@ -1,6 +1,8 @@
 package sortedmap
 
 import (
+	"math/rand"
+	"strconv"
 	"testing"
 
 	igrmkTreeMap "github.com/igrmk/treemap/v2"
@@ -25,6 +27,21 @@ func BenchmarkNoLockSet_Contains(b *testing.B) {
 	}
 }
 
+func BenchmarkFrozenSet_Contains(b *testing.B) {
+	set := NewNoLockSortedSet[int](SetContainsSize)
+	for i := 0; i < set.Capacity(); i++ {
+		set.Insert(i * 3)
+	}
+	f := set.Freeze()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sinkSContains = f.Contains(300)
+		sinkSContains = f.Contains(500)
+		sinkSContains = f.Contains(700)
+	}
+}
+
 func BenchmarkSet_Contains(b *testing.B) {
 	set := NewSortedSet[int](SetContainsSize)
 	for i := 0; i < set.Capacity(); i++ {
@@ -81,4 +98,34 @@ func BenchmarkOkAvlTreeSet_Contains(b *testing.B) {
 	}
 }
 
+// BenchmarkLargeSet_ContainsMiss looks up keys which are mostly absent.
+func BenchmarkLargeSet_ContainsMiss(b *testing.B) {
+	for _, size := range largeSizes {
+		set := NewNoLockSortedSet[int](size)
+		for i := 0; i < size; i++ {
+			set.Insert(i * 2)
+		}
+		filtered := set.Clone()
+		filtered.EnableBloomFilter(0.01)
+
+		rnd := rand.New(rand.NewSource(1))
+		keys := make([]int, 4096)
+		for i := range keys {
+			keys[i] = rnd.Intn(size)*2 + 1
+		}
+
+		b.Run("NoLockSet/"+strconv.Itoa(size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				sinkSContains = set.Contains(keys[i%len(keys)])
+			}
+		})
+
+		b.Run("BloomFilter/"+strconv.Itoa(size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				sinkSContains = filtered.Contains(keys[i%len(keys)])
+			}
+		})
+	}
+}
+
 var sinkSContains = false
@@ -1,6 +1,7 @@
 package sortedmap
 
 import (
+	"strconv"
 	"testing"
 
 	igrmkTreeMap "github.com/igrmk/treemap/v2"
@@ -88,3 +89,51 @@ func BenchmarkOkAvlTreeSet_Init(b *testing.B) {
 		okAvlTree.NewAVLTreeOrderedKey[int, struct{}]()
 	}
 }
+
+func BenchmarkLargeSet_InsertDelete(b *testing.B) {
+	for _, size := range largeSizes {
+		keys := largeKeys(size)
+
+		b.Run("NoLockSet/"+strconv.Itoa(size), func(b *testing.B) {
+			set := NewNoLockSortedSet[int](size + 1)
+			for i := 0; i < size; i++ {
+				set.Insert(i * 2)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				key := keys[i%len(keys)]
+				set.Insert(key)
+				set.Delete(key)
+			}
+		})
+
+		b.Run("ChunkedSet/"+strconv.Itoa(size), func(b *testing.B) {
+			set := NewChunkedSortedSet[int]()
+			for i := 0; i < size; i++ {
+				set.Insert(i * 2)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				key := keys[i%len(keys)]
+				set.Insert(key)
+				set.Delete(key)
+			}
+		})
+
+		b.Run("IgrmkTreeMap/"+strconv.Itoa(size), func(b *testing.B) {
+			set := igrmkTreeMap.New[int, struct{}]()
+			for i := 0; i < size; i++ {
+				set.Set(i*2, struct{}{})
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				key := keys[i%len(keys)]
+				set.Set(key, struct{}{})
+				set.Del(key)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package sortedmap
+
+import (
+	"math"
+	"math/bits"
+	"reflect"
+	"unsafe"
+
+	"golang.org/x/exp/constraints"
+)
+
+const (
+	defaultFalsePositiveRate = 0.01
+	minBloomEntries          = 64
+)
+
+// BloomFilterStats describes the Bloom filter enabled by EnableBloomFilter.
+type BloomFilterStats struct {
+	// Bytes is the memory used by the filter.
+	Bytes   int
+	Hashes  int
+	Entries int // number of keys the filter is sized for
+	// FalsePositiveRate is the configured rate, and EstimatedFalsePositiveRate
+	// the one expected from the bits currently set, which includes the keys
+	// deleted since the last rebuild.
+	FalsePositiveRate          float64
+	EstimatedFalsePositiveRate float64
+}
+
+// bloomFilter prefilters the misses of Contains. Deleted keys cannot be
+// removed from it, so it is rebuilt from the keys once they make up half of
+// the entries it is sized for, and likewise once inserts reach its size. All
+// methods are no-ops on nil.
+type bloomFilter[K constraints.Ordered] struct {
+	bits   []uint64
+	hashes int
+	hash   func(K) uint64
+	rate   float64
+	// entries is the number of keys the filter is sized for, and added the
+	// number of keys added since it was built, deleted ones included.
+	entries int
+	added   int
+	stale   int
+}
+
+func newBloomFilter[K constraints.Ordered](rate float64, keys []K) *bloomFilter[K] {
+	if !(rate > 0 && rate < 1) {
+		rate = defaultFalsePositiveRate
+	}
+	f := &bloomFilter[K]{hash: hashOf[K](), rate: rate}
+	f.rebuild(keys)
+	return f
+}
+
+func (f *bloomFilter[K]) clone() *bloomFilter[K] {
+	if f == nil {
+		return nil
+	}
+	c := *f
+	c.bits = append([]uint64(nil), f.bits...)
+	return &c
+}
+
+// rebuild sizes the filter for twice the number of keys and adds them.
+func (f *bloomFilter[K]) rebuild(keys []K) {
+	f.entries = 2 * len(keys)
+	if f.entries < minBloomEntries {
+		f.entries = minBloomEntries
+	}
+	// m = -n ln(p) / ln(2)^2, rounded up to a power of two to index by mask
+	m := uint(math.Ceil(-float64(f.entries) * math.Log(f.rate) / (math.Ln2 * math.Ln2)))
+	m = 1 << bits.Len(m-1)
+	if m < 64 {
+		m = 64
+	}
+	f.bits = make([]uint64, m/64)
+	// k = m/n ln(2), at least 1 and bounded to keep lookups cheap
+	f.hashes = int(math.Round(float64(m) / float64(f.entries) * math.Ln2))
+	if f.hashes < 1 {
+		f.hashes = 1
+	}
+	f.hashes = minInt(f.hashes, 16)
+	f.added = 0
+	f.stale = 0
+	for i := range keys {
+		f.add(keys[i])
+	}
+}
+
+func (f *bloomFilter[K]) add(key K) {
+	mask := uint64(len(f.bits)*64 - 1)
+	h1, h2 := f.split(key)
+	for i := 0; i < f.hashes; i++ {
+		bit := (h1 + uint64(i)*h2) & mask
+		f.bits[bit/64] |= 1 << (bit % 64)
+	}
+	f.added++
+}
+
+func (f *bloomFilter[K]) mayContain(key K) bool {
+	if f == nil {
+		return true
+	}
+	mask := uint64(len(f.bits)*64 - 1)
+	h1, h2 := f.split(key)
+	for i := 0; i < f.hashes; i++ {
+		bit := (h1 + uint64(i)*h2) & mask
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// split derives the two hashes of the Kirsch-Mitzenmacher scheme.
+func (f *bloomFilter[K]) split(key K) (uint64, uint64) {
+	h := f.hash(key)
+	return h, bits.RotateLeft64(h, 32) | 1
+}
+
+// inserted adds key, which keys already contains.
+func (f *bloomFilter[K]) inserted(key K, keys []K) {
+	if f == nil {
+		return
+	}
+	if f.added >= f.entries {
+		f.rebuild(keys)
+		return
+	}
+	f.add(key)
+}
+
+// deleted records that n keys were removed from keys.
+func (f *bloomFilter[K]) deleted(n int, keys []K) {
+	if f == nil {
+		return
+	}
+	f.stale += n
+	if 2*f.stale >= f.entries {
+		f.rebuild(keys)
+	}
+}
+
+func (f *bloomFilter[K]) replaced(keys []K) {
+	if f == nil {
+		return
+	}
+	f.rebuild(keys)
+}
+
+func (f *bloomFilter[K]) stats() BloomFilterStats {
+	if f == nil {
+		return BloomFilterStats{}
+	}
+	set := 0
+	for _, w := range f.bits {
+		set += bits.OnesCount64(w)
+	}
+	return BloomFilterStats{
+		Bytes:                      len(f.bits) * 8,
+		Hashes:                     f.hashes,
+		Entries:                    f.entries,
+		FalsePositiveRate:          f.rate,
+		EstimatedFalsePositiveRate: math.Pow(float64(set)/float64(len(f.bits)*64), float64(f.hashes)),
+	}
+}
+
+// hashOf returns a hash of K consistent with ==, treating -0 and +0 alike.
+func hashOf[K constraints.Ordered]() func(K) uint64 {
+	var zero K
+	switch reflect.TypeOf(zero).Kind() {
+	case reflect.String:
+		return func(k K) uint64 {
+			s := *(*string)(unsafe.Pointer(&k))
+			h := uint64(14695981039346656037)
+			for i := 0; i < len(s); i++ {
+				h ^= uint64(s[i])
+				h *= 1099511628211
+			}
+			return mix64(h)
+		}
+	case reflect.Float32:
+		return func(k K) uint64 {
+			f := *(*float32)(unsafe.Pointer(&k))
+			if f == 0 {
+				f = 0
+			}
+			return mix64(uint64(math.Float32bits(f)))
+		}
+	case reflect.Float64:
+		return func(k K) uint64 {
+			f := *(*float64)(unsafe.Pointer(&k))
+			if f == 0 {
+				f = 0
+			}
+			return mix64(math.Float64bits(f))
+		}
+	}
+	// Integers: read the raw bits, zero extended to 64 bits.
+	size := unsafe.Sizeof(zero)
+	return func(k K) uint64 {
+		return mix64(rawBits(unsafe.Pointer(&k), size))
+	}
+}
+
+// mix64 is the finalizer of SplitMix64.
+func mix64(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
+
+// EnableBloomFilter makes Contains answer most misses from a Bloom filter
+// of the values, without searching them. Rates outside (0, 1) select 1%.
+// Calling it again rebuilds the filter with the new rate. It panics on a set
+// created WithComparator, whose equality the filter cannot hash.
+func (s *NoLockSortedSet[K]) EnableBloomFilter(falsePositiveRate float64) {
+	if s.layout != nil && s.layout.custom {
+		panic("sortedmap: Bloom filter cannot be used with a custom comparator")
+	}
+	s.filter = newBloomFilter(falsePositiveRate, s.values)
+}
+
+func (s *NoLockSortedSet[K]) DisableBloomFilter() {
+	s.filter = nil
+}
+
+func (s *NoLockSortedSet[K]) BloomFilterStats() BloomFilterStats {
+	return s.filter.stats()
+}
+
+func (s *SortedSet[K]) EnableBloomFilter(falsePositiveRate float64) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.s.EnableBloomFilter(falsePositiveRate)
+}
+
+func (s *SortedSet[K]) DisableBloomFilter() {
+	s.m.Lock()
+	s.s.DisableBloomFilter()
+	s.m.Unlock()
+}
+
+func (s *SortedSet[K]) BloomFilterStats() BloomFilterStats {
+	s.m.RLock()
+	res := s.s.BloomFilterStats()
+	s.m.RUnlock()
+	return res
+}
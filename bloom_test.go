@@ -0,0 +1,118 @@
+package sortedmap_test
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/sapphi-red/sortedmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoLockSortedSet_EnableBloomFilter(t *testing.T) {
+	t.Parallel()
+
+	rnd := rand.New(rand.NewSource(1))
+	expected := sortedmap.NewNoLockSortedSet[int](0)
+	set := sortedmap.NewNoLockSortedSet[int](0)
+	set.EnableBloomFilter(0.01)
+	set.EnableStats()
+
+	// grow, then shrink, so that the filter is rebuilt both ways
+	for i := 0; i < 40000; i++ {
+		key := rnd.Intn(20000)
+		if rnd.Intn(40000) < 40000-i {
+			assert.Equal(t, expected.Insert(key), set.Insert(key))
+		} else {
+			assert.Equal(t, expected.Delete(key), set.Delete(key))
+		}
+		if i%4000 == 0 {
+			for key := 0; key < 20000; key += 7 {
+				assert.Equal(t, expected.Contains(key), set.Contains(key))
+			}
+		}
+	}
+	set.Apply(sortedmap.NewSetBatch[int]().DeleteRange(0, 9999))
+	expected.Apply(sortedmap.NewSetBatch[int]().DeleteRange(0, 9999))
+	for key := 0; key < 20000; key++ {
+		assert.Equal(t, expected.Contains(key), set.Contains(key))
+	}
+
+	stats := set.Stats()
+	assert.Greater(t, stats.FilterRejects, uint64(0))
+	assert.LessOrEqual(t, stats.FilterRejects, stats.Misses)
+}
+
+func TestNoLockSortedSet_BloomFilterStats(t *testing.T) {
+	t.Parallel()
+
+	set := sortedmap.NewNoLockSortedSet[string](0)
+	assert.Equal(t, sortedmap.BloomFilterStats{}, set.BloomFilterStats())
+
+	for i := 0; i < 10000; i++ {
+		set.Insert(strconv.Itoa(i * 2))
+	}
+	set.EnableBloomFilter(0.01)
+	set.EnableStats()
+
+	info := set.BloomFilterStats()
+	assert.Equal(t, 0.01, info.FalsePositiveRate)
+	assert.Equal(t, 20000, info.Entries)
+	// 9.6 bits per entry rounded up to a power of two
+	assert.Equal(t, 1<<15, info.Bytes)
+	assert.Less(t, info.EstimatedFalsePositiveRate, 0.01)
+
+	for i := 0; i < 10000; i++ {
+		assert.False(t, set.Contains(strconv.Itoa(i*2+1)))
+	}
+	assert.Greater(t, set.Stats().FilterRejects, uint64(9900))
+
+	set.DisableBloomFilter()
+	assert.Equal(t, 0, set.BloomFilterStats().Bytes)
+	assert.True(t, set.Contains("42"))
+}
+
+func TestSortedSet_EnableBloomFilter(t *testing.T) {
+	t.Parallel()
+
+	set := sortedmap.NewSortedSetWithOptions[float64](sortedmap.WithDescending())
+	set.EnableBloomFilter(0)
+	assert.Equal(t, 0.01, set.BloomFilterStats().FalsePositiveRate)
+
+	set.InsertAll([]float64{-0.0, 1.5, 3})
+	assert.True(t, set.Contains(0))
+	assert.True(t, set.Contains(1.5))
+	assert.False(t, set.Contains(2))
+
+	assert.NoError(t, set.Update(func(tx *sortedmap.NoLockSortedSet[float64]) error {
+		tx.Insert(2)
+		tx.Delete(3)
+		return nil
+	}))
+	assert.True(t, set.Contains(2))
+	assert.False(t, set.Contains(3))
+
+	set.Clear()
+	assert.False(t, set.Contains(2))
+	assert.Equal(t, 0, set.Clone().Size())
+
+	custom := sortedmap.NewNoLockSortedSetWithOptions[int](sortedmap.WithComparator(func(a int, b int) int {
+		return a%10 - b%10
+	}))
+	assert.Panics(t, func() {
+		custom.EnableBloomFilter(0.01)
+	})
+	custom.Insert(1)
+	assert.True(t, custom.Contains(11))
+	assert.Equal(t, 0, custom.BloomFilterStats().Bytes)
+
+	lockedCustom := sortedmap.NewSortedSetWithOptions[int](sortedmap.WithComparator(func(a int, b int) int {
+		return a%10 - b%10
+	}))
+	assert.Panics(t, func() {
+		lockedCustom.EnableBloomFilter(0.01)
+	})
+	// the lock is released by the panic
+	lockedCustom.Insert(1)
+	assert.True(t, lockedCustom.Contains(11))
+}
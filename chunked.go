@@ -0,0 +1,497 @@
+package sortedmap
+
+import (
+	"math/bits"
+	"sort"
+
+	"golang.org/x/exp/constraints"
+)
+
+// maxChunkSize bounds the entries moved by a single insert or delete of the
+// chunked backend.
+const maxChunkSize = 512
+
+type Backend int
+
+const (
+	// BackendSlice keeps the entries in one sorted slice. Lookups and
+	// iteration are the fastest, but inserts and deletes move the whole tail.
+	BackendSlice Backend = iota
+	// BackendChunked keeps the entries in sorted chunks of up to 512 entries,
+	// so that inserts and deletes stay fast on large containers.
+	BackendChunked
+)
+
+// WithBackend selects the storage used by NewSortedSetI and NewSortedMapI.
+// The other constructors, including those of the locked wrappers, always
+// use BackendSlice.
+func WithBackend(backend Backend) Option {
+	return func(o *options) {
+		o.backend = backend
+	}
+}
+
+type chunk[K constraints.Ordered, V any] struct {
+	keys   []K
+	values []V
+}
+
+func newChunk[K constraints.Ordered, V any]() *chunk[K, V] {
+	return &chunk[K, V]{
+		keys:   make([]K, 0, maxChunkSize+1),
+		values: make([]V, 0, maxChunkSize+1),
+	}
+}
+
+// chunkSizes is a Fenwick tree of the chunk sizes, so that the index of the
+// first entry of a chunk is found and updated in O(log chunk count).
+// sizes[i] holds the sum of the sizes of the chunks in (i-lowbit(i), i].
+type chunkSizes []int
+
+func newChunkSizes[K constraints.Ordered, V any](chunks []*chunk[K, V]) chunkSizes {
+	f := make(chunkSizes, len(chunks)+1)
+	for i, c := range chunks {
+		f[i+1] += len(c.keys)
+		if j := (i + 1) + (i+1)&-(i+1); j < len(f) {
+			f[j] += f[i+1]
+		}
+	}
+	return f
+}
+
+func (f chunkSizes) add(ci int, delta int) {
+	for i := ci + 1; i < len(f); i += i & -i {
+		f[i] += delta
+	}
+}
+
+// offset returns the index of the first entry of chunk ci.
+func (f chunkSizes) offset(ci int) int {
+	pos := 0
+	for i := ci; i > 0; i -= i & -i {
+		pos += f[i]
+	}
+	return pos
+}
+
+// locate returns the chunk holding the entry at index pos, or the chunk
+// count if pos is past the end.
+func (f chunkSizes) locate(pos int) int {
+	if len(f) <= 1 {
+		return 0
+	}
+	ci := 0
+	for step := 1 << (bits.Len(uint(len(f)-1)) - 1); step > 0; step >>= 1 {
+		if ci+step < len(f) && f[ci+step] <= pos {
+			ci += step
+			pos -= f[ci]
+		}
+	}
+	return ci
+}
+
+// chunkList is the storage of the chunked backend. No chunk is empty.
+type chunkList[K constraints.Ordered, V any] struct {
+	chunks []*chunk[K, V]
+	sizes  chunkSizes
+	size   int
+	layout *layout[K, V]
+}
+
+func (l *chunkList[K, V]) clear() {
+	l.chunks = nil
+	l.sizes = nil
+	l.size = 0
+}
+
+func (l *chunkList[K, V]) capacity() int {
+	c := 0
+	for _, ch := range l.chunks {
+		c += cap(ch.values)
+	}
+	return c
+}
+
+// search returns the chunk and the position in it where key is or would be
+// inserted.
+func (l *chunkList[K, V]) search(key K) (int, int, bool) {
+	if len(l.chunks) == 0 {
+		return 0, 0, false
+	}
+	ci := sort.Search(len(l.chunks), func(i int) bool {
+		keys := l.chunks[i].keys
+		return l.layout.compare(keys[len(keys)-1], key) >= 0
+	})
+	if ci == len(l.chunks) {
+		ci--
+		return ci, len(l.chunks[ci].keys), false
+	}
+	i, exists := l.layout.search(l.chunks[ci].keys, key)
+	return ci, i, exists
+}
+
+func (l *chunkList[K, V]) index(ci int, i int) int {
+	if len(l.chunks) == 0 {
+		return 0
+	}
+	return l.sizes.offset(ci) + i
+}
+
+func (l *chunkList[K, V]) indexOfGreaterOrEqual(key K) int {
+	ci, i, _ := l.search(key)
+	return l.index(ci, i)
+}
+
+func (l *chunkList[K, V]) indexOfGreater(key K) int {
+	ci, i, exists := l.search(key)
+	if exists {
+		i++ // does not include multiple same values
+	}
+	return l.index(ci, i)
+}
+
+func (l *chunkList[K, V]) contains(key K) bool {
+	_, _, exists := l.search(key)
+	return exists
+}
+
+// insert returns the index of the new entry, or -1 if key exists or the
+// maximum capacity is reached.
+func (l *chunkList[K, V]) insert(key K, value V) int {
+	ci, i, exists := l.search(key)
+	if exists || l.layout.limit(l.size+1) <= l.size {
+		return -1
+	}
+	if len(l.chunks) == 0 {
+		l.chunks = []*chunk[K, V]{newChunk[K, V]()}
+		l.sizes = newChunkSizes(l.chunks)
+	}
+
+	c := l.chunks[ci]
+	c.keys = insertAt(c.keys, i, key)
+	c.values = insertAt(c.values, i, value)
+	pos := l.index(ci, i)
+	l.sizes.add(ci, 1)
+	l.size++
+
+	if len(c.keys) > maxChunkSize {
+		l.split(ci)
+	}
+	return pos
+}
+
+func (l *chunkList[K, V]) split(ci int) {
+	c := l.chunks[ci]
+	half := len(c.keys) / 2
+	right := newChunk[K, V]()
+	right.keys = append(right.keys, c.keys[half:]...)
+	right.values = append(right.values, c.values[half:]...)
+	clearTail(c.keys, half)
+	clearTail(c.values, half)
+	c.keys = c.keys[:half]
+	c.values = c.values[:half]
+
+	l.chunks = insertAt(l.chunks, ci+1, right)
+	l.sizes = newChunkSizes(l.chunks)
+}
+
+// delete returns the index the entry had, or -1 if key does not exist.
+func (l *chunkList[K, V]) delete(key K) int {
+	ci, i, exists := l.search(key)
+	if !exists {
+		return -1
+	}
+
+	c := l.chunks[ci]
+	c.keys = deleteAt(c.keys, i)
+	c.values = deleteAt(c.values, i)
+	clearTail(c.keys[:len(c.keys)+1], len(c.keys))
+	clearTail(c.values[:len(c.values)+1], len(c.values))
+	pos := l.index(ci, i)
+	l.sizes.add(ci, -1)
+	l.size--
+
+	switch {
+	case len(c.keys) == 0:
+		l.chunks = deleteAt(l.chunks, ci)
+		l.sizes = newChunkSizes(l.chunks)
+	case len(c.keys) < maxChunkSize/4:
+		if ci+1 < len(l.chunks) && len(c.keys)+len(l.chunks[ci+1].keys) <= maxChunkSize/2 {
+			l.merge(ci)
+		} else if ci > 0 && len(c.keys)+len(l.chunks[ci-1].keys) <= maxChunkSize/2 {
+			l.merge(ci - 1)
+		}
+	}
+	return pos
+}
+
+// merge moves the entries of chunks[ci+1] into chunks[ci].
+func (l *chunkList[K, V]) merge(ci int) {
+	c, next := l.chunks[ci], l.chunks[ci+1]
+	c.keys = append(c.keys, next.keys...)
+	c.values = append(c.values, next.values...)
+	l.chunks = deleteAt(l.chunks, ci+1)
+	l.sizes = newChunkSizes(l.chunks)
+}
+
+// keysBetween copies the keys whose indexes are in [start, end).
+func (l *chunkList[K, V]) keysBetween(start int, end int) []K {
+	if end <= start {
+		return []K{}
+	}
+	res := make([]K, 0, end-start)
+	ci := l.sizes.locate(start)
+	for offset := l.sizes.offset(ci); ci < len(l.chunks) && offset < end; ci++ {
+		from, to := l.chunkRange(ci, offset, start, end)
+		res = append(res, l.chunks[ci].keys[from:to]...)
+		offset += len(l.chunks[ci].keys)
+	}
+	return res
+}
+
+// valuesBetween copies the values whose indexes are in [start, end).
+func (l *chunkList[K, V]) valuesBetween(start int, end int) []V {
+	if end <= start {
+		return []V{}
+	}
+	res := make([]V, 0, end-start)
+	ci := l.sizes.locate(start)
+	for offset := l.sizes.offset(ci); ci < len(l.chunks) && offset < end; ci++ {
+		from, to := l.chunkRange(ci, offset, start, end)
+		res = append(res, l.chunks[ci].values[from:to]...)
+		offset += len(l.chunks[ci].values)
+	}
+	return res
+}
+
+// chunkRange returns the part of chunk ci, whose first entry is at index
+// offset, that lies in [start, end).
+func (l *chunkList[K, V]) chunkRange(ci int, offset int, start int, end int) (int, int) {
+	from := start - offset
+	if from < 0 {
+		from = 0
+	}
+	to := minInt(end-offset, len(l.chunks[ci].keys))
+	return from, to
+}
+
+// clearTail zeroes s[from:] so that removed entries can be collected.
+func clearTail[T any](s []T, from int) {
+	var zero T
+	for i := from; i < len(s); i++ {
+		s[i] = zero
+	}
+}
+
+// ChunkedSortedMap has the API of NoLockSortedMap but stores the entries in
+// chunks, so inserts and deletes cost O(log n + chunk size) instead of O(n).
+// The slices returned by the Get methods are copies. Chunks are always
+// allocated at their full size, so WithCapacity and ExtendCapacityTo have no
+// effect; of the other options, only the comparator and maximum capacity
+// ones are honored.
+type ChunkedSortedMap[K constraints.Ordered, V any] struct {
+	l chunkList[K, V]
+}
+
+func NewChunkedSortedMap[K constraints.Ordered, V any](opts ...Option) *ChunkedSortedMap[K, V] {
+	l, _ := newLayout[K, V](opts)
+	return &ChunkedSortedMap[K, V]{l: chunkList[K, V]{layout: l}}
+}
+
+func (s *ChunkedSortedMap[K, V]) Size() int {
+	return s.l.size
+}
+
+func (s *ChunkedSortedMap[K, V]) Capacity() int {
+	return s.l.capacity()
+}
+
+// ExtendCapacityTo does nothing, see ChunkedSortedMap.
+func (s *ChunkedSortedMap[K, V]) ExtendCapacityTo(newCap int) {
+}
+
+func (s *ChunkedSortedMap[K, V]) Clear() {
+	s.l.clear()
+}
+
+func (s *ChunkedSortedMap[K, V]) Insert(key K, value V) int {
+	return s.l.insert(key, value)
+}
+
+// InsertWithAfterHint is the same as Insert. The chunk search is already
+// cheap, so the hint is not used.
+func (s *ChunkedSortedMap[K, V]) InsertWithAfterHint(key K, value V, afterIndex int) int {
+	return s.l.insert(key, value)
+}
+
+func (s *ChunkedSortedMap[K, V]) Delete(key K) int {
+	return s.l.delete(key)
+}
+
+// DeleteWithAfterHint is the same as Delete.
+func (s *ChunkedSortedMap[K, V]) DeleteWithAfterHint(key K, afterIndex int) int {
+	return s.l.delete(key)
+}
+
+func (s *ChunkedSortedMap[K, V]) InsertAll(keys []K, values []V) {
+	for i := range keys {
+		s.l.insert(keys[i], values[i])
+	}
+}
+
+func (s *ChunkedSortedMap[K, V]) InsertAllByMap(m map[K]V) {
+	for k, v := range m {
+		s.l.insert(k, v)
+	}
+}
+
+func (s *ChunkedSortedMap[K, V]) InsertAllOrdered(keys []K, values []V) {
+	s.InsertAll(keys, values)
+}
+
+func (s *ChunkedSortedMap[K, V]) DeleteAll(keys []K) {
+	for i := range keys {
+		s.l.delete(keys[i])
+	}
+}
+
+func (s *ChunkedSortedMap[K, V]) DeleteAllOrdered(keys []K) {
+	s.DeleteAll(keys)
+}
+
+func (s *ChunkedSortedMap[K, V]) Contains(key K) bool {
+	return s.l.contains(key)
+}
+
+func (s *ChunkedSortedMap[K, V]) GetIndexOfGreater(key K) int {
+	return s.l.indexOfGreater(key)
+}
+func (s *ChunkedSortedMap[K, V]) GetIndexOfGreaterOrEqual(key K) int {
+	return s.l.indexOfGreaterOrEqual(key)
+}
+
+func (s *ChunkedSortedMap[K, V]) GetGreater(key K) []V {
+	return s.l.valuesBetween(s.l.indexOfGreater(key), s.l.size)
+}
+func (s *ChunkedSortedMap[K, V]) GetGreaterOrEqual(key K) []V {
+	return s.l.valuesBetween(s.l.indexOfGreaterOrEqual(key), s.l.size)
+}
+func (s *ChunkedSortedMap[K, V]) GetLess(key K) []V {
+	return s.l.valuesBetween(0, s.l.indexOfGreaterOrEqual(key))
+}
+func (s *ChunkedSortedMap[K, V]) GetLessOrEqual(key K) []V {
+	return s.l.valuesBetween(0, s.l.indexOfGreater(key))
+}
+
+func (s *ChunkedSortedMap[K, V]) GetByInclusiveRange(startKey K, endKey K) []V {
+	return s.l.valuesBetween(s.l.indexOfGreaterOrEqual(startKey), s.l.indexOfGreater(endKey))
+}
+
+// ChunkedSortedSet is the chunked counterpart of NoLockSortedSet. See
+// ChunkedSortedMap.
+type ChunkedSortedSet[K constraints.Ordered] struct {
+	l chunkList[K, struct{}]
+}
+
+func NewChunkedSortedSet[K constraints.Ordered](opts ...Option) *ChunkedSortedSet[K] {
+	l, _ := newLayout[K, struct{}](opts)
+	return &ChunkedSortedSet[K]{l: chunkList[K, struct{}]{layout: l}}
+}
+
+func (s *ChunkedSortedSet[K]) Size() int {
+	return s.l.size
+}
+
+func (s *ChunkedSortedSet[K]) Capacity() int {
+	return s.l.capacity()
+}
+
+// ExtendCapacityTo does nothing, see ChunkedSortedMap.
+func (s *ChunkedSortedSet[K]) ExtendCapacityTo(newCap int) {
+}
+
+func (s *ChunkedSortedSet[K]) Clear() {
+	s.l.clear()
+}
+
+func (s *ChunkedSortedSet[K]) Insert(value K) int {
+	return s.l.insert(value, struct{}{})
+}
+
+// InsertWithAfterHint is the same as Insert.
+func (s *ChunkedSortedSet[K]) InsertWithAfterHint(value K, afterIndex int) int {
+	return s.l.insert(value, struct{}{})
+}
+
+func (s *ChunkedSortedSet[K]) Delete(value K) int {
+	return s.l.delete(value)
+}
+
+// DeleteWithAfterHint is the same as Delete.
+func (s *ChunkedSortedSet[K]) DeleteWithAfterHint(value K, afterIndex int) int {
+	return s.l.delete(value)
+}
+
+func (s *ChunkedSortedSet[K]) InsertAll(values []K) {
+	for i := range values {
+		s.l.insert(values[i], struct{}{})
+	}
+}
+
+func (s *ChunkedSortedSet[K]) InsertAllOrdered(values []K) {
+	s.InsertAll(values)
+}
+
+func (s *ChunkedSortedSet[K]) DeleteAll(values []K) {
+	for i := range values {
+		s.l.delete(values[i])
+	}
+}
+
+func (s *ChunkedSortedSet[K]) DeleteAllOrdered(values []K) {
+	s.DeleteAll(values)
+}
+
+func (s *ChunkedSortedSet[K]) Contains(value K) bool {
+	return s.l.contains(value)
+}
+
+func (s *ChunkedSortedSet[K]) GetIndexOfGreater(value K) int {
+	return s.l.indexOfGreater(value)
+}
+func (s *ChunkedSortedSet[K]) GetIndexOfGreaterOrEqual(value K) int {
+	return s.l.indexOfGreaterOrEqual(value)
+}
+
+func (s *ChunkedSortedSet[K]) GetGreater(value K) []K {
+	return s.l.keysBetween(s.l.indexOfGreater(value), s.l.size)
+}
+func (s *ChunkedSortedSet[K]) GetGreaterOrEqual(value K) []K {
+	return s.l.keysBetween(s.l.indexOfGreaterOrEqual(value), s.l.size)
+}
+func (s *ChunkedSortedSet[K]) GetLess(value K) []K {
+	return s.l.keysBetween(0, s.l.indexOfGreaterOrEqual(value))
+}
+func (s *ChunkedSortedSet[K]) GetLessOrEqual(value K) []K {
+	return s.l.keysBetween(0, s.l.indexOfGreater(value))
+}
+
+func (s *ChunkedSortedSet[K]) GetByInclusiveRange(startValue K, endValue K) []K {
+	return s.l.keysBetween(s.l.indexOfGreaterOrEqual(startValue), s.l.indexOfGreater(endValue))
+}
+
+// NewSortedSetI creates a set with the backend chosen by WithBackend.
+func NewSortedSetI[K constraints.Ordered](opts ...Option) SortedSetI[K] {
+	if resolveOptions(opts).backend == BackendChunked {
+		return NewChunkedSortedSet[K](opts...)
+	}
+	return NewNoLockSortedSetWithOptions[K](opts...)
+}
+
+// NewSortedMapI creates a map with the backend chosen by WithBackend.
+func NewSortedMapI[K constraints.Ordered, V any](opts ...Option) SortedMapI[K, V] {
+	if resolveOptions(opts).backend == BackendChunked {
+		return NewChunkedSortedMap[K, V](opts...)
+	}
+	return NewNoLockSortedMapWithOptions[K, V](opts...)
+}
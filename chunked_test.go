@@ -0,0 +1,72 @@
+package sortedmap_test
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/sapphi-red/sortedmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunkedSortedMap(t *testing.T) {
+	t.Parallel()
+
+	rnd := rand.New(rand.NewSource(1))
+	expected := sortedmap.NewNoLockSortedMap[int, string](0)
+	actual := sortedmap.NewSortedMapI[int, string](sortedmap.WithBackend(sortedmap.BackendChunked))
+	assert.IsType(t, &sortedmap.ChunkedSortedMap[int, string]{}, actual)
+
+	for i := 0; i < 20000; i++ {
+		key := rnd.Intn(5000)
+		// grow first, then shrink to exercise the merges
+		if rnd.Intn(20000) < 20000-i {
+			assert.Equal(t, expected.Insert(key, strconv.Itoa(key)), actual.Insert(key, strconv.Itoa(key)))
+		} else {
+			assert.Equal(t, expected.Delete(key), actual.Delete(key))
+		}
+		if i%1000 == 0 {
+			assert.Equal(t, expected.Size(), actual.Size())
+			assert.Equal(t, expected.GetGreaterOrEqual(-1), actual.GetGreaterOrEqual(-1))
+		}
+	}
+
+	assert.Equal(t, expected.Size(), actual.Size())
+	for _, key := range []int{-1, 0, 1, 700, 2500, 4999, 5000} {
+		assert.Equal(t, expected.Contains(key), actual.Contains(key))
+		assert.Equal(t, expected.GetIndexOfGreater(key), actual.GetIndexOfGreater(key))
+		assert.Equal(t, expected.GetIndexOfGreaterOrEqual(key), actual.GetIndexOfGreaterOrEqual(key))
+		assert.Equal(t, expected.GetGreater(key), actual.GetGreater(key))
+		assert.Equal(t, expected.GetLess(key), actual.GetLess(key))
+		assert.Equal(t, expected.GetLessOrEqual(key), actual.GetLessOrEqual(key))
+		assert.Equal(t, expected.GetByInclusiveRange(key, key+600), actual.GetByInclusiveRange(key, key+600))
+	}
+
+	actual.Clear()
+	assert.Equal(t, 0, actual.Size())
+	assert.Equal(t, []string{}, actual.GetGreaterOrEqual(0))
+	assert.Equal(t, 0, actual.GetIndexOfGreater(0))
+}
+
+func TestChunkedSortedSet(t *testing.T) {
+	t.Parallel()
+
+	set := sortedmap.NewSortedSetI[int](sortedmap.WithBackend(sortedmap.BackendChunked), sortedmap.WithDescending())
+	values := make([]int, 2000)
+	for i := range values {
+		values[i] = i
+	}
+	set.InsertAll(values)
+	assert.Equal(t, -1, set.Insert(5))
+	assert.Equal(t, 2000, set.Size())
+	assert.Equal(t, []int{1999, 1998}, set.GetGreaterOrEqual(1999)[:2])
+	assert.Equal(t, []int{2, 1, 0}, set.GetGreater(3))
+	assert.Equal(t, 1999, set.Delete(0))
+	set.DeleteAll(values[:1990])
+	assert.Equal(t, []int{1999, 1998, 1997, 1996, 1995, 1994, 1993, 1992, 1991, 1990}, set.GetGreaterOrEqual(5000))
+
+	assert.IsType(t, &sortedmap.NoLockSortedSet[int]{}, sortedmap.NewSortedSetI[int]())
+	limited := sortedmap.NewChunkedSortedSet[int](sortedmap.WithMaxCapacity(1))
+	assert.Equal(t, 0, limited.Insert(1))
+	assert.Equal(t, -1, limited.Insert(2))
+}
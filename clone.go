@@ -0,0 +1,183 @@
+package sortedmap
+
+// compactCapacity returns the capacity for size entries so that they fill at
+// least targetLoad of it, or -1 if capacity already does.
+func compactCapacity(size int, capacity int, targetLoad float64) int {
+	if targetLoad <= 0 || targetLoad > 1 {
+		targetLoad = 1
+	}
+	if float64(size) >= float64(capacity)*targetLoad {
+		return -1
+	}
+	newCap := int(float64(size) / targetLoad)
+	if float64(newCap)*targetLoad < float64(size) {
+		newCap++
+	}
+	return newCap
+}
+
+// Clone returns an independent copy without observers or stats.
+func (s *NoLockSortedSet[K]) Clone() *NoLockSortedSet[K] {
+	c := s.clone(0)
+	c.stats = nil
+	return c
+}
+
+func (s *NoLockSortedSet[K]) ShrinkToFit() {
+	s.Compact(1)
+}
+
+// Compact reallocates the backing slice when less than targetLoad of it is
+// used, leaving the entries to fill targetLoad of the new one.
+func (s *NoLockSortedSet[K]) Compact(targetLoad float64) {
+	newCap := compactCapacity(len(s.values), cap(s.values), targetLoad)
+	if newCap < 0 {
+		return
+	}
+	s.values = append(s.layout.makeKeys(newCap), s.values...)
+	s.stats.resized()
+}
+
+// Clone returns an independent copy without observers or stats. The values
+// are copied as is.
+func (s *NoLockSortedMap[K, V]) Clone() *NoLockSortedMap[K, V] {
+	c := s.clone(0)
+	c.stats = nil
+	return c
+}
+
+// CloneFunc is like Clone but copies each value with copyValue.
+func (s *NoLockSortedMap[K, V]) CloneFunc(copyValue func(V) V) *NoLockSortedMap[K, V] {
+	c := s.Clone()
+	for i := range c.values {
+		c.values[i] = copyValue(c.values[i])
+	}
+	return c
+}
+
+func (s *NoLockSortedMap[K, V]) ShrinkToFit() {
+	s.Compact(1)
+}
+
+// Compact reallocates the backing slices when less than targetLoad of them is
+// used, leaving the entries to fill targetLoad of the new ones.
+func (s *NoLockSortedMap[K, V]) Compact(targetLoad float64) {
+	newCap := compactCapacity(len(s.values), cap(s.values), targetLoad)
+	if newCap < 0 {
+		return
+	}
+	s.keys = append(s.layout.makeKeys(newCap), s.keys...)
+	s.values = append(s.layout.makeValues(newCap), s.values...)
+	s.stats.resized()
+}
+
+// Clone returns an independent copy without observers or stats. The values
+// are copied as is.
+func (s *NoLockSortedMapCalc[K, V]) Clone() *NoLockSortedMapCalc[K, V] {
+	c := s.clone(0)
+	c.stats = nil
+	return c
+}
+
+// CloneFunc is like Clone but copies each value with copyValue, which must
+// not change the calculated key.
+func (s *NoLockSortedMapCalc[K, V]) CloneFunc(copyValue func(V) V) *NoLockSortedMapCalc[K, V] {
+	c := s.Clone()
+	for i := range c.values {
+		c.values[i] = copyValue(c.values[i])
+	}
+	return c
+}
+
+func (s *NoLockSortedMapCalc[K, V]) ShrinkToFit() {
+	s.Compact(1)
+}
+
+// Compact reallocates the backing slices when less than targetLoad of them is
+// used, leaving the entries to fill targetLoad of the new ones.
+func (s *NoLockSortedMapCalc[K, V]) Compact(targetLoad float64) {
+	newCap := compactCapacity(len(s.values), cap(s.values), targetLoad)
+	if newCap < 0 {
+		return
+	}
+	s.keys = append(s.layout.makeKeys(newCap), s.keys...)
+	s.values = append(s.layout.makeValues(newCap), s.values...)
+	s.stats.resized()
+}
+
+// Clone returns an independent NoLockSortedSet taken under the read lock.
+func (s *SortedSet[K]) Clone() *NoLockSortedSet[K] {
+	s.m.RLock()
+	c := s.s.Clone()
+	s.m.RUnlock()
+	return c
+}
+
+func (s *SortedSet[K]) ShrinkToFit() {
+	s.m.Lock()
+	s.s.ShrinkToFit()
+	s.m.Unlock()
+}
+
+func (s *SortedSet[K]) Compact(targetLoad float64) {
+	s.m.Lock()
+	s.s.Compact(targetLoad)
+	s.m.Unlock()
+}
+
+// Clone returns an independent NoLockSortedMap taken under the read lock.
+func (s *SortedMap[K, V]) Clone() *NoLockSortedMap[K, V] {
+	s.m.RLock()
+	c := s.s.Clone()
+	s.m.RUnlock()
+	return c
+}
+
+// CloneFunc is like Clone but copies each value with copyValue.
+func (s *SortedMap[K, V]) CloneFunc(copyValue func(V) V) *NoLockSortedMap[K, V] {
+	s.m.RLock()
+	c := s.s.CloneFunc(copyValue)
+	s.m.RUnlock()
+	return c
+}
+
+func (s *SortedMap[K, V]) ShrinkToFit() {
+	s.m.Lock()
+	s.s.ShrinkToFit()
+	s.m.Unlock()
+}
+
+func (s *SortedMap[K, V]) Compact(targetLoad float64) {
+	s.m.Lock()
+	s.s.Compact(targetLoad)
+	s.m.Unlock()
+}
+
+// Clone returns an independent NoLockSortedMapCalc taken under the read lock.
+func (s *SortedMapCalc[K, V]) Clone() *NoLockSortedMapCalc[K, V] {
+	s.m.RLock()
+	c := s.s.Clone()
+	s.m.RUnlock()
+	return c
+}
+
+// CloneFunc is like Clone but copies each value with copyValue, which must
+// not change the calculated key.
+func (s *SortedMapCalc[K, V]) CloneFunc(copyValue func(V) V) *NoLockSortedMapCalc[K, V] {
+	s.m.RLock()
+	c := s.s.CloneFunc(copyValue)
+	s.m.RUnlock()
+	return c
+}
+
+func (s *SortedMapCalc[K, V]) ShrinkToFit() {
+	s.m.Lock()
+	s.s.ShrinkToFit()
+	s.m.Unlock()
+}
+
+func (s *SortedMapCalc[K, V]) Compact(targetLoad float64) {
+	s.m.Lock()
+	s.s.Compact(targetLoad)
+	s.m.Unlock()
+}
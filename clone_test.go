@@ -0,0 +1,97 @@
+package sortedmap_test
+
+import (
+	"testing"
+
+	"github.com/sapphi-red/sortedmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoLockSortedMap_Clone(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewNoLockSortedMap[int, []int](5)
+	m.Insert(1, []int{1})
+	m.Insert(2, []int{2})
+
+	shallow := m.Clone()
+	deep := m.CloneFunc(func(v []int) []int {
+		return append([]int(nil), v...)
+	})
+	m.Delete(2)
+	m.GetGreaterOrEqual(1)[0][0] = 10
+
+	assert.Equal(t, 2, shallow.Size())
+	assert.Equal(t, [][]int{{10}, {2}}, shallow.GetGreaterOrEqual(0))
+	assert.Equal(t, [][]int{{1}, {2}}, deep.GetGreaterOrEqual(0))
+}
+
+func TestNoLockSortedSet_Compact(t *testing.T) {
+	t.Parallel()
+
+	set := sortedmap.NewNoLockSortedSet[int](100)
+	set.InsertAll([]int{1, 2, 3, 4, 5, 6})
+
+	set.Compact(0.5)
+	assert.Equal(t, 12, set.Capacity())
+	set.Compact(0.5)
+	assert.Equal(t, 12, set.Capacity())
+
+	set.ShrinkToFit()
+	assert.Equal(t, 6, set.Capacity())
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6}, set.GetGreaterOrEqual(0))
+}
+
+func TestSortedMapCalc_Clone(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMapCalc(50, safeAtoi)
+	m.InsertAll([]string{"1", "2"})
+
+	c := m.Clone()
+	assert.IsType(t, &sortedmap.NoLockSortedMapCalc[int, string]{}, c)
+	m.Delete("1")
+	assert.Equal(t, []string{"2"}, m.GetGreaterOrEqual(0))
+	assert.Equal(t, []string{"1", "2"}, c.GetGreaterOrEqual(0))
+	c.Insert("3")
+	assert.Equal(t, 1, m.Size())
+
+	deep := m.CloneFunc(func(v string) string { return v })
+	assert.IsType(t, &sortedmap.NoLockSortedMapCalc[int, string]{}, deep)
+	assert.Equal(t, []string{"2"}, deep.GetGreaterOrEqual(0))
+
+	m.ShrinkToFit()
+	assert.Equal(t, 1, m.Capacity())
+	c.Compact(0.75)
+	assert.Equal(t, 4, c.Capacity())
+}
+
+func TestSortedSet_Clone(t *testing.T) {
+	t.Parallel()
+
+	set := sortedmap.NewSortedSet[int](5)
+	set.InsertAll([]int{1, 2})
+	c := set.Clone()
+	assert.IsType(t, &sortedmap.NoLockSortedSet[int]{}, c)
+	set.Clear()
+	assert.Equal(t, []int{1, 2}, c.GetGreaterOrEqual(0))
+}
+
+func TestSortedMap_Clone(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMap[int, []int](5)
+	m.Insert(1, []int{1})
+
+	shallow := m.Clone()
+	deep := m.CloneFunc(func(v []int) []int {
+		return append([]int(nil), v...)
+	})
+	assert.IsType(t, &sortedmap.NoLockSortedMap[int, []int]{}, shallow)
+	assert.IsType(t, &sortedmap.NoLockSortedMap[int, []int]{}, deep)
+
+	m.GetGreaterOrEqual(0)[0][0] = 10
+	m.Delete(1)
+	assert.Equal(t, [][]int{{10}}, shallow.GetGreaterOrEqual(0))
+	assert.Equal(t, [][]int{{1}}, deep.GetGreaterOrEqual(0))
+}
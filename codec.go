@@ -0,0 +1,149 @@
+package sortedmap
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+
+	"golang.org/x/exp/constraints"
+)
+
+type Codec[T any] interface {
+	Encode(w io.Writer, v T) error
+	Decode(r io.Reader) (T, error)
+}
+
+type CodecFuncs[T any] struct {
+	EncodeFunc func(w io.Writer, v T) error
+	DecodeFunc func(r io.Reader) (T, error)
+}
+
+func (c CodecFuncs[T]) Encode(w io.Writer, v T) error {
+	return c.EncodeFunc(w, v)
+}
+
+func (c CodecFuncs[T]) Decode(r io.Reader) (T, error) {
+	return c.DecodeFunc(r)
+}
+
+var errCodecOverflow = errors.New("sortedmap: decoded value overflows type")
+
+type orderedCodec[T constraints.Ordered] struct{}
+
+// OrderedCodec encodes integers as varints, floats as their IEEE 754 bits
+// and strings as a length-prefixed byte sequence.
+func OrderedCodec[T constraints.Ordered]() Codec[T] {
+	return orderedCodec[T]{}
+}
+
+func (orderedCodec[T]) Encode(w io.Writer, v T) error {
+	var buf [binary.MaxVarintLen64]byte
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := binary.PutVarint(buf[:], rv.Int())
+		_, err := w.Write(buf[:n])
+		return err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n := binary.PutUvarint(buf[:], rv.Uint())
+		_, err := w.Write(buf[:n])
+		return err
+	case reflect.Float32:
+		binary.LittleEndian.PutUint32(buf[:], math.Float32bits(float32(rv.Float())))
+		_, err := w.Write(buf[:4])
+		return err
+	case reflect.Float64:
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(rv.Float()))
+		_, err := w.Write(buf[:8])
+		return err
+	default:
+		s := rv.String()
+		n := binary.PutUvarint(buf[:], uint64(len(s)))
+		if _, err := w.Write(buf[:n]); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, s)
+		return err
+	}
+}
+
+func (orderedCodec[T]) Decode(r io.Reader) (T, error) {
+	var v T
+	rv := reflect.ValueOf(&v).Elem()
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, err := binary.ReadVarint(asByteReader(r))
+		if err != nil {
+			return v, unexpectedEOF(err)
+		}
+		if rv.OverflowInt(x) {
+			return v, errCodecOverflow
+		}
+		rv.SetInt(x)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		x, err := binary.ReadUvarint(asByteReader(r))
+		if err != nil {
+			return v, unexpectedEOF(err)
+		}
+		if rv.OverflowUint(x) {
+			return v, errCodecOverflow
+		}
+		rv.SetUint(x)
+	case reflect.Float32:
+		var buf [4]byte
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return v, unexpectedEOF(err)
+		}
+		rv.SetFloat(float64(math.Float32frombits(binary.LittleEndian.Uint32(buf[:]))))
+	case reflect.Float64:
+		var buf [8]byte
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return v, unexpectedEOF(err)
+		}
+		rv.SetFloat(math.Float64frombits(binary.LittleEndian.Uint64(buf[:])))
+	default:
+		n, err := binary.ReadUvarint(asByteReader(r))
+		if err != nil {
+			return v, unexpectedEOF(err)
+		}
+		if n > math.MaxInt32 {
+			return v, errCodecOverflow
+		}
+		buf := make([]byte, 0, minInt(int(n), 1<<16))
+		for uint64(len(buf)) < n {
+			chunk := minInt(int(n)-len(buf), 1<<16)
+			buf = append(buf, make([]byte, chunk)...)
+			if _, err := io.ReadFull(r, buf[len(buf)-chunk:]); err != nil {
+				return v, unexpectedEOF(err)
+			}
+		}
+		rv.SetString(string(buf))
+	}
+	return v, nil
+}
+
+type byteReader struct {
+	r   io.Reader
+	buf [1]byte
+}
+
+func (b *byteReader) ReadByte() (byte, error) {
+	_, err := io.ReadFull(b.r, b.buf[:])
+	return b.buf[0], err
+}
+
+func asByteReader(r io.Reader) io.ByteReader {
+	if br, ok := r.(io.ByteReader); ok {
+		return br
+	}
+	return &byteReader{r: r}
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,229 @@
+package sortedmap
+
+import "errors"
+
+// ErrKeyExists is returned by SortedMapCalc when a stored value would get the
+// calculated key of another entry, which it would otherwise overwrite.
+var ErrKeyExists = errors.New("sortedmap: calculated key belongs to another entry")
+
+type ComputeOp int
+
+const (
+	ComputeKeep ComputeOp = iota
+	ComputeStore
+	ComputeDelete
+)
+
+func (s *NoLockSortedMap[K, V]) get(key K) (V, int, bool) {
+	pos, exists := s.layout.search(s.keys, key)
+	s.stats.lookup(exists)
+	if !exists {
+		var zero V
+		return zero, pos, false
+	}
+	return s.values[pos], pos, true
+}
+
+// LoadOrStore returns the value of key if present. Otherwise it stores value
+// and returns it, or returns ErrMaxCapacity when the map is full.
+func (s *SortedMap[K, V]) LoadOrStore(key K, value V) (V, bool, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	actual, pos, loaded := s.s.get(key)
+	if loaded {
+		return actual, true, nil
+	}
+	if !s.s.insertAtPos(pos, key, value) {
+		var zero V
+		return zero, false, ErrMaxCapacity
+	}
+	return value, false, nil
+}
+
+func (s *SortedMap[K, V]) LoadAndDelete(key K) (V, bool) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	value, pos, loaded := s.s.get(key)
+	if loaded {
+		s.s.deleteAtPos(pos)
+	}
+	return value, loaded
+}
+
+func (s *SortedMap[K, V]) CompareAndSwap(key K, old V, new V, eq func(V, V) bool) bool {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	current, pos, loaded := s.s.get(key)
+	if !loaded || !eq(current, old) {
+		return false
+	}
+	s.s.updateAt(pos, new)
+	return true
+}
+
+func (s *SortedMap[K, V]) CompareAndDelete(key K, old V, eq func(V, V) bool) bool {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	current, pos, loaded := s.s.get(key)
+	if !loaded || !eq(current, old) {
+		return false
+	}
+	s.s.deleteAtPos(pos)
+	return true
+}
+
+// Compute calls fn with the current value of key and applies the returned
+// ComputeOp. It returns the value stored after the call and whether the key
+// is present. When a new key does not fit, nothing changes and it returns
+// ErrMaxCapacity.
+func (s *SortedMap[K, V]) Compute(key K, fn func(old V, loaded bool) (V, ComputeOp)) (V, bool, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	old, pos, loaded := s.s.get(key)
+	value, op := fn(old, loaded)
+	switch op {
+	case ComputeStore:
+		if loaded {
+			s.s.updateAt(pos, value)
+		} else if !s.s.insertAtPos(pos, key, value) {
+			return old, false, ErrMaxCapacity
+		}
+		return value, true, nil
+	case ComputeDelete:
+		if loaded {
+			s.s.deleteAtPos(pos)
+		}
+		var zero V
+		return zero, false, nil
+	default:
+		return old, loaded, nil
+	}
+}
+
+func (s *NoLockSortedMapCalc[K, V]) get(key K) (V, int, bool) {
+	pos, exists := s.layout.search(s.keys, key)
+	s.stats.lookup(exists)
+	if !exists {
+		var zero V
+		return zero, pos, false
+	}
+	return s.values[pos], pos, true
+}
+
+// replaceAt replaces the entry at pos. A value with a different calculated
+// key is moved to its own position, unless another entry has that key.
+func (s *NoLockSortedMapCalc[K, V]) replaceAt(pos int, value V) error {
+	key := s.calcKey(value)
+	if key == s.keys[pos] {
+		s.updateAt(pos, value)
+		return nil
+	}
+	if _, exists := s.layout.search(s.keys, key); exists {
+		return ErrKeyExists
+	}
+	s.deleteAtPos(pos)
+	s.set(value)
+	return nil
+}
+
+// LoadOrStore returns the value having the key of value if present.
+// Otherwise it stores value and returns it, or returns ErrMaxCapacity when
+// the map is full.
+func (s *SortedMapCalc[K, V]) LoadOrStore(value V) (V, bool, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	key := s.s.calcKey(value)
+	actual, pos, loaded := s.s.get(key)
+	if loaded {
+		return actual, true, nil
+	}
+	if !s.s.insertAtPos(pos, key, value) {
+		var zero V
+		return zero, false, ErrMaxCapacity
+	}
+	return value, false, nil
+}
+
+func (s *SortedMapCalc[K, V]) LoadAndDelete(key K) (V, bool) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	value, pos, loaded := s.s.get(key)
+	if loaded {
+		s.s.deleteAtPos(pos)
+	}
+	return value, loaded
+}
+
+// CompareAndSwap replaces the value stored at key with new if it equals old.
+// When new has a different calculated key, it is stored under that key, or
+// nothing changes and it returns ErrKeyExists if another entry has it.
+func (s *SortedMapCalc[K, V]) CompareAndSwap(key K, old V, new V, eq func(V, V) bool) (bool, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	current, pos, loaded := s.s.get(key)
+	if !loaded || !eq(current, old) {
+		return false, nil
+	}
+	if err := s.s.replaceAt(pos, new); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *SortedMapCalc[K, V]) CompareAndDelete(key K, old V, eq func(V, V) bool) bool {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	current, pos, loaded := s.s.get(key)
+	if !loaded || !eq(current, old) {
+		return false
+	}
+	s.s.deleteAtPos(pos)
+	return true
+}
+
+// Compute calls fn with the current value of key and applies the returned
+// ComputeOp. It returns the value stored after the call and whether it is
+// present. A stored value is placed under its own calculated key. When that
+// key belongs to another entry or a new key does not fit, nothing changes
+// and it returns ErrKeyExists or ErrMaxCapacity.
+func (s *SortedMapCalc[K, V]) Compute(key K, fn func(old V, loaded bool) (V, ComputeOp)) (V, bool, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	old, pos, loaded := s.s.get(key)
+	value, op := fn(old, loaded)
+	switch op {
+	case ComputeStore:
+		if loaded {
+			if err := s.s.replaceAt(pos, value); err != nil {
+				return old, true, err
+			}
+			return value, true, nil
+		}
+		newPos, exists := s.s.layout.search(s.s.keys, s.s.calcKey(value))
+		if exists {
+			return old, false, ErrKeyExists
+		}
+		if !s.s.insertAtPos(newPos, s.s.calcKey(value), value) {
+			return old, false, ErrMaxCapacity
+		}
+		return value, true, nil
+	case ComputeDelete:
+		if loaded {
+			s.s.deleteAtPos(pos)
+		}
+		var zero V
+		return zero, false, nil
+	default:
+		return old, loaded, nil
+	}
+}
@@ -0,0 +1,191 @@
+package sortedmap_test
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/sapphi-red/sortedmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func eqString(a, b string) bool {
+	return a == b
+}
+
+func TestSortedMap_LoadOrStore(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMap[int, string](5)
+	actual, loaded, err := m.LoadOrStore(1, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", actual)
+	assert.Equal(t, false, loaded)
+
+	actual, loaded, err = m.LoadOrStore(1, "one")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", actual)
+	assert.Equal(t, true, loaded)
+}
+
+func TestSortedMap_LoadAndDelete(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMap[int, string](5)
+	m.Insert(1, "1")
+	value, loaded := m.LoadAndDelete(1)
+	assert.Equal(t, "1", value)
+	assert.Equal(t, true, loaded)
+
+	_, loaded = m.LoadAndDelete(1)
+	assert.Equal(t, false, loaded)
+	assert.Equal(t, 0, m.Size())
+}
+
+func TestSortedMap_CompareAndSwap(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMap[int, string](5)
+	m.Insert(1, "1")
+	assert.Equal(t, false, m.CompareAndSwap(1, "2", "3", eqString))
+	assert.Equal(t, false, m.CompareAndSwap(2, "1", "3", eqString))
+	assert.Equal(t, true, m.CompareAndSwap(1, "1", "3", eqString))
+	assert.Equal(t, []string{"3"}, m.GetGreaterOrEqual(0))
+
+	assert.Equal(t, false, m.CompareAndDelete(1, "1", eqString))
+	assert.Equal(t, true, m.CompareAndDelete(1, "3", eqString))
+	assert.Equal(t, 0, m.Size())
+}
+
+func TestSortedMap_Compute(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMap[int, int](5)
+	increment := func(old int, loaded bool) (int, sortedmap.ComputeOp) {
+		return old + 1, sortedmap.ComputeStore
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				m.Compute(1, increment)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, []int{800}, m.GetGreaterOrEqual(0))
+
+	value, ok, err := m.Compute(1, func(old int, loaded bool) (int, sortedmap.ComputeOp) {
+		return 0, sortedmap.ComputeKeep
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 800, value)
+	assert.Equal(t, true, ok)
+
+	_, ok, err = m.Compute(1, func(old int, loaded bool) (int, sortedmap.ComputeOp) {
+		return 0, sortedmap.ComputeDelete
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, false, ok)
+	assert.Equal(t, false, m.Contains(1))
+}
+
+func TestSortedMapCalc_LoadOrStore(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMapCalc(5, safeAtoi)
+	actual, loaded, err := m.LoadOrStore("1")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", actual)
+	assert.Equal(t, false, loaded)
+
+	actual, loaded, err = m.LoadOrStore("01")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", actual)
+	assert.Equal(t, true, loaded)
+
+	value, loaded := m.LoadAndDelete(1)
+	assert.Equal(t, "1", value)
+	assert.Equal(t, true, loaded)
+	assert.Equal(t, 0, m.Size())
+}
+
+func TestSortedMapCalc_CompareAndSwap(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMapCalc(5, safeAtoi)
+	m.InsertAll([]string{"1", "3"})
+	swapped, err := m.CompareAndSwap(1, "01", "001", eqString)
+	assert.NoError(t, err)
+	assert.Equal(t, false, swapped)
+	swapped, err = m.CompareAndSwap(1, "1", "01", eqString)
+	assert.NoError(t, err)
+	assert.Equal(t, true, swapped)
+	assert.Equal(t, []string{"01", "3"}, m.GetGreaterOrEqual(0))
+
+	swapped, err = m.CompareAndSwap(1, "01", "2", eqString)
+	assert.NoError(t, err)
+	assert.Equal(t, true, swapped)
+	assert.Equal(t, []string{"2", "3"}, m.GetGreaterOrEqual(0))
+
+	// "3" already has the key of "03".
+	swapped, err = m.CompareAndSwap(2, "2", "03", eqString)
+	assert.ErrorIs(t, err, sortedmap.ErrKeyExists)
+	assert.Equal(t, false, swapped)
+	assert.Equal(t, []string{"2", "3"}, m.GetGreaterOrEqual(0))
+
+	assert.Equal(t, false, m.CompareAndDelete(2, "02", eqString))
+	assert.Equal(t, true, m.CompareAndDelete(2, "2", eqString))
+	assert.Equal(t, []string{"3"}, m.GetGreaterOrEqual(0))
+}
+
+func TestSortedMapCalc_Compute(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMapCalc(5, safeAtoi)
+	value, ok, err := m.Compute(5, func(old string, loaded bool) (string, sortedmap.ComputeOp) {
+		assert.Equal(t, false, loaded)
+		return "5", sortedmap.ComputeStore
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "5", value)
+	assert.Equal(t, true, ok)
+
+	_, _, err = m.Compute(5, func(old string, loaded bool) (string, sortedmap.ComputeOp) {
+		return strconv.Itoa(safeAtoi(old) + 1), sortedmap.ComputeStore
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"6"}, m.GetGreaterOrEqual(0))
+
+	_, ok, err = m.Compute(6, func(old string, loaded bool) (string, sortedmap.ComputeOp) {
+		return "", sortedmap.ComputeDelete
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, false, ok)
+	assert.Equal(t, 0, m.Size())
+}
+
+func TestSortedMapCalc_ComputeKeyExists(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMapCalc(5, safeAtoi)
+	m.InsertAll([]string{"1", "2"})
+
+	value, ok, err := m.Compute(1, func(old string, loaded bool) (string, sortedmap.ComputeOp) {
+		return "02", sortedmap.ComputeStore
+	})
+	assert.ErrorIs(t, err, sortedmap.ErrKeyExists)
+	assert.Equal(t, "1", value)
+	assert.Equal(t, true, ok)
+
+	value, ok, err = m.Compute(3, func(old string, loaded bool) (string, sortedmap.ComputeOp) {
+		return "2", sortedmap.ComputeStore
+	})
+	assert.ErrorIs(t, err, sortedmap.ErrKeyExists)
+	assert.Equal(t, "", value)
+	assert.Equal(t, false, ok)
+	assert.Equal(t, []string{"1", "2"}, m.GetGreaterOrEqual(0))
+}
@@ -0,0 +1,152 @@
+package sortedmap
+
+import "golang.org/x/exp/constraints"
+
+// diffSorted walks both key slices sorted by cmp in lockstep and reports every
+// difference to fn until it returns false. The Index of an event is the
+// position in a after the previous events were applied, so replaying them in
+// order turns a into b. It returns whether the walk finished.
+func diffSorted[K constraints.Ordered, V any](
+	aKeys []K, aValues []V, bKeys []K, bValues []V,
+	cmp func(K, K) int, eq func(V, V) bool, fn func(ChangeEvent[K, V]) bool,
+) bool {
+	i, j, pos := 0, 0, 0
+	for i < len(aKeys) || j < len(bKeys) {
+		switch {
+		case j == len(bKeys) || (i < len(aKeys) && cmp(aKeys[i], bKeys[j]) < 0):
+			if !fn(ChangeEvent[K, V]{Kind: ChangeDelete, Index: pos, Key: aKeys[i], Value: aValues[i]}) {
+				return false
+			}
+			i++
+		case i == len(aKeys) || cmp(aKeys[i], bKeys[j]) > 0:
+			if !fn(ChangeEvent[K, V]{Kind: ChangeInsert, Index: pos, Key: bKeys[j], Value: bValues[j]}) {
+				return false
+			}
+			j++
+			pos++
+		default:
+			if eq != nil && !eq(aValues[i], bValues[j]) {
+				if !fn(ChangeEvent[K, V]{Kind: ChangeUpdate, Index: pos, Key: aKeys[i], Value: bValues[j], OldValue: aValues[i]}) {
+					return false
+				}
+			}
+			i++
+			j++
+			pos++
+		}
+	}
+	return true
+}
+
+// SetDiff holds the values only in b as Added and the values only in a as
+// Removed. The diffs walk both containers in the order of a, so they must be
+// ordered the same way.
+type SetDiff[K constraints.Ordered] struct {
+	Added   []K
+	Removed []K
+}
+
+// MapDiff holds the entries only in b as Added, the entries only in a as
+// Removed and the entries whose values differ as Changed, each in key order.
+type MapDiff[K constraints.Ordered, V any] struct {
+	Added   []ChangeEvent[K, V]
+	Removed []ChangeEvent[K, V]
+	Changed []ChangeEvent[K, V]
+}
+
+func (d *MapDiff[K, V]) add(e ChangeEvent[K, V]) bool {
+	switch e.Kind {
+	case ChangeInsert:
+		d.Added = append(d.Added, e)
+	case ChangeDelete:
+		d.Removed = append(d.Removed, e)
+	case ChangeUpdate:
+		d.Changed = append(d.Changed, e)
+	}
+	return true
+}
+
+func (d MapDiff[K, V]) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+func EqualSet[K constraints.Ordered](a *NoLockSortedSet[K], b *NoLockSortedSet[K]) bool {
+	if len(a.values) != len(b.values) {
+		return false
+	}
+	for i := range a.values {
+		if a.values[i] != b.values[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// DiffSetFunc calls fn with the changes turning a into b until fn returns
+// false. The Value and OldValue of the events are always empty.
+func DiffSetFunc[K constraints.Ordered](a *NoLockSortedSet[K], b *NoLockSortedSet[K], fn func(ChangeEvent[K, struct{}]) bool) {
+	diffSorted(a.values, make([]struct{}, len(a.values)), b.values, make([]struct{}, len(b.values)), a.layout.compare, nil, fn)
+}
+
+func DiffSet[K constraints.Ordered](a *NoLockSortedSet[K], b *NoLockSortedSet[K]) SetDiff[K] {
+	var d SetDiff[K]
+	DiffSetFunc(a, b, func(e ChangeEvent[K, struct{}]) bool {
+		if e.Kind == ChangeInsert {
+			d.Added = append(d.Added, e.Key)
+		} else {
+			d.Removed = append(d.Removed, e.Key)
+		}
+		return true
+	})
+	return d
+}
+
+// EqualMap reports whether a and b hold the same keys with values equal by
+// eq. A nil eq compares the keys only, so maps whose values differ are still
+// equal.
+func EqualMap[K constraints.Ordered, V any](a *NoLockSortedMap[K, V], b *NoLockSortedMap[K, V], eq func(V, V) bool) bool {
+	if len(a.keys) != len(b.keys) {
+		return false
+	}
+	return diffSorted(a.keys, a.values, b.keys, b.values, a.layout.compare, eq, func(ChangeEvent[K, V]) bool {
+		return false
+	})
+}
+
+// DiffMapFunc calls fn with the changes turning a into b until fn returns
+// false. Values are compared with eq. A nil eq compares the keys only and
+// reports no ChangeUpdate.
+func DiffMapFunc[K constraints.Ordered, V any](a *NoLockSortedMap[K, V], b *NoLockSortedMap[K, V], eq func(V, V) bool, fn func(ChangeEvent[K, V]) bool) {
+	diffSorted(a.keys, a.values, b.keys, b.values, a.layout.compare, eq, fn)
+}
+
+func DiffMap[K constraints.Ordered, V any](a *NoLockSortedMap[K, V], b *NoLockSortedMap[K, V], eq func(V, V) bool) MapDiff[K, V] {
+	var d MapDiff[K, V]
+	DiffMapFunc(a, b, eq, d.add)
+	return d
+}
+
+// EqualMapCalc reports whether a and b hold the same keys with values equal
+// by eq. A nil eq compares the keys only, so maps whose values differ are
+// still equal.
+func EqualMapCalc[K constraints.Ordered, V any](a *NoLockSortedMapCalc[K, V], b *NoLockSortedMapCalc[K, V], eq func(V, V) bool) bool {
+	if len(a.keys) != len(b.keys) {
+		return false
+	}
+	return diffSorted(a.keys, a.values, b.keys, b.values, a.layout.compare, eq, func(ChangeEvent[K, V]) bool {
+		return false
+	})
+}
+
+// DiffMapCalcFunc calls fn with the changes turning a into b until fn returns
+// false. Values are compared with eq. A nil eq compares the keys only and
+// reports no ChangeUpdate.
+func DiffMapCalcFunc[K constraints.Ordered, V any](a *NoLockSortedMapCalc[K, V], b *NoLockSortedMapCalc[K, V], eq func(V, V) bool, fn func(ChangeEvent[K, V]) bool) {
+	diffSorted(a.keys, a.values, b.keys, b.values, a.layout.compare, eq, fn)
+}
+
+func DiffMapCalc[K constraints.Ordered, V any](a *NoLockSortedMapCalc[K, V], b *NoLockSortedMapCalc[K, V], eq func(V, V) bool) MapDiff[K, V] {
+	var d MapDiff[K, V]
+	DiffMapCalcFunc(a, b, eq, d.add)
+	return d
+}
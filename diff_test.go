@@ -0,0 +1,110 @@
+package sortedmap_test
+
+import (
+	"testing"
+
+	"github.com/sapphi-red/sortedmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffSet(t *testing.T) {
+	t.Parallel()
+
+	a := sortedmap.NewNoLockSortedSet[int](5)
+	a.InsertAll([]int{1, 2, 4, 6})
+	b := sortedmap.NewNoLockSortedSet[int](5)
+	b.InsertAll([]int{0, 2, 4, 5})
+
+	assert.True(t, sortedmap.EqualSet(a, a))
+	assert.False(t, sortedmap.EqualSet(a, b))
+	assert.Equal(t, sortedmap.SetDiff[int]{Added: []int{0, 5}, Removed: []int{1, 6}}, sortedmap.DiffSet(a, b))
+}
+
+func TestDiffMap(t *testing.T) {
+	t.Parallel()
+
+	a := sortedmap.NewNoLockSortedMap[int, string](5)
+	a.InsertAll([]int{1, 2, 3, 5}, []string{"1", "2", "3", "5"})
+	b := sortedmap.NewNoLockSortedMap[int, string](5)
+	b.InsertAll([]int{2, 3, 4, 6}, []string{"2", "x", "4", "6"})
+
+	assert.True(t, sortedmap.EqualMap(a, a, eqString))
+	assert.False(t, sortedmap.EqualMap(a, b, eqString))
+
+	d := sortedmap.DiffMap(a, b, eqString)
+	assert.Equal(t, []sortedmap.ChangeEvent[int, string]{
+		{Kind: sortedmap.ChangeInsert, Index: 2, Key: 4, Value: "4"},
+		{Kind: sortedmap.ChangeInsert, Index: 3, Key: 6, Value: "6"},
+	}, d.Added)
+	assert.Equal(t, []sortedmap.ChangeEvent[int, string]{
+		{Kind: sortedmap.ChangeDelete, Index: 0, Key: 1, Value: "1"},
+		{Kind: sortedmap.ChangeDelete, Index: 3, Key: 5, Value: "5"},
+	}, d.Removed)
+	assert.Equal(t, []sortedmap.ChangeEvent[int, string]{
+		{Kind: sortedmap.ChangeUpdate, Index: 1, Key: 3, Value: "x", OldValue: "3"},
+	}, d.Changed)
+
+	// replaying the events in order turns a into b
+	values := append([]string(nil), a.GetGreaterOrEqual(0)...)
+	sortedmap.DiffMapFunc(a, b, eqString, func(e sortedmap.ChangeEvent[int, string]) bool {
+		switch e.Kind {
+		case sortedmap.ChangeInsert:
+			values = append(values[:e.Index], append([]string{e.Value}, values[e.Index:]...)...)
+		case sortedmap.ChangeDelete:
+			values = append(values[:e.Index], values[e.Index+1:]...)
+		case sortedmap.ChangeUpdate:
+			values[e.Index] = e.Value
+		}
+		return true
+	})
+	assert.Equal(t, b.GetGreaterOrEqual(0), values)
+
+	count := 0
+	sortedmap.DiffMapFunc(a, b, eqString, func(sortedmap.ChangeEvent[int, string]) bool {
+		count++
+		return false
+	})
+	assert.Equal(t, 1, count)
+}
+
+func TestDiffMapCalc(t *testing.T) {
+	t.Parallel()
+
+	a := sortedmap.NewNoLockSortedMapCalc(5, safeAtoi)
+	a.InsertAll([]string{"1", "2"})
+	b := sortedmap.NewNoLockSortedMapCalc(5, safeAtoi)
+	b.InsertAll([]string{"2", "3"})
+
+	assert.True(t, sortedmap.EqualMapCalc(a, a, eqString))
+	assert.False(t, sortedmap.EqualMapCalc(a, b, eqString))
+
+	d := sortedmap.DiffMapCalc(a, b, eqString)
+	assert.Len(t, d.Added, 1)
+	assert.Equal(t, "3", d.Added[0].Value)
+	assert.Len(t, d.Removed, 1)
+	assert.Equal(t, "1", d.Removed[0].Value)
+	assert.Empty(t, d.Changed)
+	assert.True(t, sortedmap.DiffMapCalc(a, a, eqString).Empty())
+}
+
+func TestEqualMap_NilEq(t *testing.T) {
+	t.Parallel()
+
+	a := sortedmap.NewNoLockSortedMap[int, string](5)
+	a.InsertAll([]int{1, 2}, []string{"1", "2"})
+	b := sortedmap.NewNoLockSortedMap[int, string](5)
+	b.InsertAll([]int{1, 2}, []string{"1", "x"})
+
+	assert.False(t, sortedmap.EqualMap(a, b, eqString))
+	assert.True(t, sortedmap.EqualMap(a, b, nil))
+	assert.True(t, sortedmap.DiffMap(a, b, nil).Empty())
+
+	c := sortedmap.NewNoLockSortedMapCalc(5, safeAtoi)
+	c.InsertAll([]string{"1", "2"})
+	d := sortedmap.NewNoLockSortedMapCalc(5, safeAtoi)
+	d.InsertAll([]string{"1", "02"})
+
+	assert.False(t, sortedmap.EqualMapCalc(c, d, eqString))
+	assert.True(t, sortedmap.EqualMapCalc(c, d, nil))
+	assert.True(t, sortedmap.DiffMapCalc(c, d, nil).Empty())
+}
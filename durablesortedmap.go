@@ -0,0 +1,522 @@
+package sortedmap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/constraints"
+)
+
+type SyncPolicy int
+
+const (
+	SyncAlways SyncPolicy = iota
+	SyncInterval
+	SyncNever
+)
+
+type DurableOptions struct {
+	Sync SyncPolicy
+	// SyncInterval is only used with SyncInterval. Defaults to one second.
+	SyncInterval time.Duration
+	// CheckpointEvery triggers a checkpoint after that many log records.
+	// Zero disables automatic checkpoints.
+	CheckpointEvery int
+}
+
+const (
+	durableSnapshotFile = "snapshot"
+	durableLogFile      = "wal"
+)
+
+const (
+	walOpInsert byte = iota + 1
+	walOpDelete
+	walOpClear
+)
+
+const (
+	walRecordHeaderSize = 8
+	maxWALRecordSize    = 1 << 30
+)
+
+var ErrDurableClosed = errors.New("sortedmap: durable map is closed")
+
+var errWALRecord = errors.New("sortedmap: invalid log record")
+
+// walFile is the part of *os.File used for the log.
+type walFile interface {
+	io.ReadWriteSeeker
+	io.Closer
+	Sync() error
+	Truncate(size int64) error
+}
+
+type DurableSortedMap[K constraints.Ordered, V any] struct {
+	s NoLockSortedMap[K, V]
+	m sync.RWMutex
+
+	dir        string
+	keyCodec   Codec[K]
+	valueCodec Codec[V]
+	opts       DurableOptions
+
+	log walFile
+	// offset is the end of the last complete record of the log.
+	offset  int64
+	records int
+	dirty   bool
+	closed  bool
+	// syncErr is the last error of the background sync. It is returned by
+	// every write until a Sync or Checkpoint succeeds.
+	syncErr error
+	// failed is set when a failed append could not be removed from the log,
+	// which then must not be appended to.
+	failed error
+
+	stopSync chan struct{}
+	syncDone chan struct{}
+}
+
+func OpenDurableSortedMap[K constraints.Ordered, V any](dir string, keyCodec Codec[K], valueCodec Codec[V], opts DurableOptions) (*DurableSortedMap[K, V], error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	s := &DurableSortedMap[K, V]{
+		s:          *NewNoLockSortedMap[K, V](0),
+		dir:        dir,
+		keyCodec:   keyCodec,
+		valueCodec: valueCodec,
+		opts:       opts,
+	}
+	if err := s.loadSnapshot(); err != nil {
+		return nil, err
+	}
+
+	log, err := os.OpenFile(filepath.Join(dir, durableLogFile), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	s.log = log
+	if err := s.replay(); err != nil {
+		log.Close()
+		return nil, err
+	}
+
+	if opts.Sync == SyncInterval {
+		interval := opts.SyncInterval
+		if interval <= 0 {
+			interval = time.Second
+		}
+		s.stopSync = make(chan struct{})
+		s.syncDone = make(chan struct{})
+		go s.syncLoop(interval)
+	}
+	return s, nil
+}
+
+func (s *DurableSortedMap[K, V]) loadSnapshot() error {
+	f, err := os.Open(filepath.Join(s.dir, durableSnapshotFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = s.s.Snapshot(s.keyCodec, s.valueCodec).ReadFrom(f)
+	return err
+}
+
+// replay applies every intact record of the log. A torn record at the tail,
+// left behind by a crash in the middle of an append, is truncated away.
+// Replaying records already covered by the snapshot is harmless because
+// re-applying the log in order converges to the same state.
+func (s *DurableSortedMap[K, V]) replay() error {
+	if _, err := s.log.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	r := bufio.NewReader(s.log)
+
+	var offset int64
+	var header [walRecordHeaderSize]byte
+	for {
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			break
+		}
+		size := binary.BigEndian.Uint32(header[:4])
+		if size > maxWALRecordSize {
+			break
+		}
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			break
+		}
+		if crc32.Checksum(payload, crc32c) != binary.BigEndian.Uint32(header[4:]) {
+			break
+		}
+		if err := s.applyRecord(payload); err != nil {
+			break
+		}
+		offset += walRecordHeaderSize + int64(size)
+		s.records++
+	}
+
+	s.offset = offset
+	return s.rollbackLocked()
+}
+
+// rollbackLocked cuts the log back to the end of the last complete record.
+func (s *DurableSortedMap[K, V]) rollbackLocked() error {
+	if err := s.log.Truncate(s.offset); err != nil {
+		return err
+	}
+	_, err := s.log.Seek(s.offset, io.SeekStart)
+	return err
+}
+
+func (s *DurableSortedMap[K, V]) applyRecord(payload []byte) error {
+	if len(payload) == 0 {
+		return errWALRecord
+	}
+	r := bytes.NewReader(payload[1:])
+	switch payload[0] {
+	case walOpInsert:
+		key, err := s.keyCodec.Decode(r)
+		if err != nil {
+			return err
+		}
+		value, err := s.valueCodec.Decode(r)
+		if err != nil {
+			return err
+		}
+		s.s.Insert(key, value)
+	case walOpDelete:
+		key, err := s.keyCodec.Decode(r)
+		if err != nil {
+			return err
+		}
+		s.s.Delete(key)
+	case walOpClear:
+		s.s.Clear()
+	default:
+		return errWALRecord
+	}
+	return nil
+}
+
+// appendRecord writes a record to the log. If writing or syncing it fails,
+// the record is removed again so that neither a torn record hides the later
+// ones from replay nor the failed operation comes back after reopening.
+func (s *DurableSortedMap[K, V]) appendRecord(op byte, key *K, value *V) error {
+	if err := s.checkLocked(); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	buf.Write(make([]byte, walRecordHeaderSize))
+	buf.WriteByte(op)
+	if key != nil {
+		if err := s.keyCodec.Encode(&buf, *key); err != nil {
+			return err
+		}
+	}
+	if value != nil {
+		if err := s.valueCodec.Encode(&buf, *value); err != nil {
+			return err
+		}
+	}
+
+	record := buf.Bytes()
+	payload := record[walRecordHeaderSize:]
+	binary.BigEndian.PutUint32(record[:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(record[4:8], crc32.Checksum(payload, crc32c))
+	_, err := s.log.Write(record)
+	if err == nil && s.opts.Sync == SyncAlways {
+		s.dirty = true
+		err = s.syncLocked()
+	}
+	if err != nil {
+		if rerr := s.rollbackLocked(); rerr != nil {
+			s.failed = rerr
+		}
+		return err
+	}
+	s.offset += int64(len(record))
+	s.records++
+	s.dirty = true
+	return nil
+}
+
+func (s *DurableSortedMap[K, V]) afterAppend() error {
+	if s.opts.CheckpointEvery > 0 && s.records >= s.opts.CheckpointEvery {
+		return s.checkpointLocked()
+	}
+	return nil
+}
+
+func (s *DurableSortedMap[K, V]) syncLocked() error {
+	if !s.dirty {
+		return nil
+	}
+	if err := s.log.Sync(); err != nil {
+		return err
+	}
+	s.dirty = false
+	return nil
+}
+
+func (s *DurableSortedMap[K, V]) syncLoop(interval time.Duration) {
+	defer close(s.syncDone)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.m.Lock()
+			if !s.closed {
+				if err := s.syncLocked(); err != nil {
+					s.syncErr = err
+				}
+			}
+			s.m.Unlock()
+		case <-s.stopSync:
+			return
+		}
+	}
+}
+
+// checkLocked returns ErrDurableClosed after Close, or else the error which
+// keeps the log from being written.
+func (s *DurableSortedMap[K, V]) checkLocked() error {
+	if s.closed {
+		return ErrDurableClosed
+	}
+	if s.failed != nil {
+		return s.failed
+	}
+	return s.syncErr
+}
+
+func (s *DurableSortedMap[K, V]) Insert(key K, value V) (int, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if err := s.checkLocked(); err != nil {
+		return -1, err
+	}
+	if s.s.Contains(key) {
+		return -1, nil
+	}
+	if err := s.appendRecord(walOpInsert, &key, &value); err != nil {
+		return -1, err
+	}
+	res := s.s.Insert(key, value)
+	return res, s.afterAppend()
+}
+
+func (s *DurableSortedMap[K, V]) Delete(key K) (int, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if err := s.checkLocked(); err != nil {
+		return -1, err
+	}
+	if !s.s.Contains(key) {
+		return -1, nil
+	}
+	if err := s.appendRecord(walOpDelete, &key, nil); err != nil {
+		return -1, err
+	}
+	res := s.s.Delete(key)
+	return res, s.afterAppend()
+}
+
+func (s *DurableSortedMap[K, V]) Clear() error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if err := s.checkLocked(); err != nil {
+		return err
+	}
+	if err := s.appendRecord(walOpClear, nil, nil); err != nil {
+		return err
+	}
+	s.s.Clear()
+	return s.afterAppend()
+}
+
+func (s *DurableSortedMap[K, V]) Sync() error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if s.closed {
+		return ErrDurableClosed
+	}
+	if s.failed != nil {
+		return s.failed
+	}
+	if err := s.syncLocked(); err != nil {
+		return err
+	}
+	s.syncErr = nil
+	return nil
+}
+
+func (s *DurableSortedMap[K, V]) Checkpoint() error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if s.closed {
+		return ErrDurableClosed
+	}
+	if s.failed != nil {
+		return s.failed
+	}
+	if err := s.checkpointLocked(); err != nil {
+		return err
+	}
+	s.syncErr = nil
+	return nil
+}
+
+func (s *DurableSortedMap[K, V]) checkpointLocked() error {
+	tmpPath := filepath.Join(s.dir, durableSnapshotFile+".tmp")
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	if _, err := s.s.Snapshot(s.keyCodec, s.valueCodec).WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, filepath.Join(s.dir, durableSnapshotFile)); err != nil {
+		return err
+	}
+	if err := syncDir(s.dir); err != nil {
+		return err
+	}
+
+	if err := s.log.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := s.log.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	s.offset = 0
+	s.records = 0
+	s.dirty = true
+	return s.syncLocked()
+}
+
+func syncDir(dir string) error {
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return f.Sync()
+}
+
+func (s *DurableSortedMap[K, V]) Close() error {
+	s.m.Lock()
+	if s.closed {
+		s.m.Unlock()
+		return nil
+	}
+	err := s.failed
+	if err == nil {
+		err = s.syncErr
+	}
+	s.closed = true
+	if serr := s.syncLocked(); err == nil {
+		err = serr
+	}
+	if cerr := s.log.Close(); err == nil {
+		err = cerr
+	}
+	s.m.Unlock()
+
+	if s.stopSync != nil {
+		close(s.stopSync)
+		<-s.syncDone
+	}
+	return err
+}
+
+func (s *DurableSortedMap[K, V]) Size() int {
+	s.m.RLock()
+	l := s.s.Size()
+	s.m.RUnlock()
+	return l
+}
+
+func (s *DurableSortedMap[K, V]) Contains(key K) bool {
+	s.m.RLock()
+	res := s.s.Contains(key)
+	s.m.RUnlock()
+	return res
+}
+
+func (s *DurableSortedMap[K, V]) GetIndexOfGreater(key K) int {
+	s.m.RLock()
+	res := s.s.GetIndexOfGreater(key)
+	s.m.RUnlock()
+	return res
+}
+func (s *DurableSortedMap[K, V]) GetIndexOfGreaterOrEqual(key K) int {
+	s.m.RLock()
+	res := s.s.GetIndexOfGreaterOrEqual(key)
+	s.m.RUnlock()
+	return res
+}
+
+func (s *DurableSortedMap[K, V]) GetGreater(key K) []V {
+	s.m.RLock()
+	res := s.s.GetGreater(key)
+	s.m.RUnlock()
+	return res
+}
+func (s *DurableSortedMap[K, V]) GetGreaterOrEqual(key K) []V {
+	s.m.RLock()
+	res := s.s.GetGreaterOrEqual(key)
+	s.m.RUnlock()
+	return res
+}
+func (s *DurableSortedMap[K, V]) GetLess(key K) []V {
+	s.m.RLock()
+	res := s.s.GetLess(key)
+	s.m.RUnlock()
+	return res
+}
+func (s *DurableSortedMap[K, V]) GetLessOrEqual(key K) []V {
+	s.m.RLock()
+	res := s.s.GetLessOrEqual(key)
+	s.m.RUnlock()
+	return res
+}
+
+func (s *DurableSortedMap[K, V]) GetByInclusiveRange(startKey K, endKey K) []V {
+	s.m.RLock()
+	res := s.s.GetByInclusiveRange(startKey, endKey)
+	s.m.RUnlock()
+	return res
+}
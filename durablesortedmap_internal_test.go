@@ -0,0 +1,119 @@
+package sortedmap
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errFaultyLog = errors.New("faulty log")
+
+// faultyLog writes only half of a record when failWrite is set and fails
+// every sync when failSync is set.
+type faultyLog struct {
+	walFile
+	failWrite bool
+	failSync  bool
+}
+
+func (f *faultyLog) Write(p []byte) (int, error) {
+	if f.failWrite {
+		n, _ := f.walFile.Write(p[:len(p)/2])
+		return n, errFaultyLog
+	}
+	return f.walFile.Write(p)
+}
+
+func (f *faultyLog) Sync() error {
+	if f.failSync {
+		return errFaultyLog
+	}
+	return f.walFile.Sync()
+}
+
+func openFaultyDurable(t *testing.T, dir string, opts DurableOptions) (*DurableSortedMap[int, string], *faultyLog) {
+	m, err := OpenDurableSortedMap(dir, OrderedCodec[int](), OrderedCodec[string](), opts)
+	assert.NoError(t, err)
+	m.m.Lock()
+	log := &faultyLog{walFile: m.log}
+	m.log = log
+	m.m.Unlock()
+	return m, log
+}
+
+func TestDurableSortedMap_FailedWrite(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	m, log := openFaultyDurable(t, dir, DurableOptions{Sync: SyncNever})
+	_, err := m.Insert(1, "1")
+	assert.NoError(t, err)
+	log.failWrite = true
+	_, err = m.Insert(2, "2")
+	assert.ErrorIs(t, err, errFaultyLog)
+	log.failWrite = false
+	_, err = m.Insert(3, "3")
+	assert.NoError(t, err)
+	assert.NoError(t, m.Close())
+
+	m, _ = openFaultyDurable(t, dir, DurableOptions{})
+	assert.Equal(t, []string{"1", "3"}, m.GetGreaterOrEqual(0))
+	assert.NoError(t, m.Close())
+}
+
+func TestDurableSortedMap_FailedSync(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	m, log := openFaultyDurable(t, dir, DurableOptions{Sync: SyncAlways})
+	_, err := m.Insert(1, "1")
+	assert.NoError(t, err)
+	log.failSync = true
+	_, err = m.Insert(2, "2")
+	assert.ErrorIs(t, err, errFaultyLog)
+	assert.ErrorIs(t, m.Clear(), errFaultyLog)
+	assert.Equal(t, []string{"1"}, m.GetGreaterOrEqual(0))
+	log.failSync = false
+	assert.NoError(t, m.Close())
+
+	m, _ = openFaultyDurable(t, dir, DurableOptions{})
+	assert.Equal(t, []string{"1"}, m.GetGreaterOrEqual(0))
+	assert.NoError(t, m.Close())
+}
+
+func TestDurableSortedMap_SyncLoopError(t *testing.T) {
+	t.Parallel()
+
+	m, log := openFaultyDurable(t, t.TempDir(), DurableOptions{
+		Sync:         SyncInterval,
+		SyncInterval: time.Millisecond,
+	})
+
+	m.m.Lock()
+	log.failSync = true
+	m.dirty = true
+	m.m.Unlock()
+
+	assert.Eventually(t, func() bool {
+		m.m.RLock()
+		defer m.m.RUnlock()
+		return m.syncErr != nil
+	}, time.Second, time.Millisecond)
+
+	// The error stays until a sync succeeds.
+	_, err := m.Insert(1, "1")
+	assert.ErrorIs(t, err, errFaultyLog)
+	_, err = m.Insert(1, "1")
+	assert.ErrorIs(t, err, errFaultyLog)
+	assert.False(t, m.Contains(1))
+
+	m.m.Lock()
+	log.failSync = false
+	m.m.Unlock()
+	assert.NoError(t, m.Sync())
+	_, err = m.Insert(1, "1")
+	assert.NoError(t, err)
+	assert.NoError(t, m.Close())
+}
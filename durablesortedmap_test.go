@@ -0,0 +1,102 @@
+package sortedmap_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sapphi-red/sortedmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func openDurable(t *testing.T, dir string, opts sortedmap.DurableOptions) *sortedmap.DurableSortedMap[int, string] {
+	m, err := sortedmap.OpenDurableSortedMap(dir, sortedmap.OrderedCodec[int](), sortedmap.OrderedCodec[string](), opts)
+	assert.NoError(t, err)
+	return m
+}
+
+func TestDurableSortedMap_Reopen(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	m := openDurable(t, dir, sortedmap.DurableOptions{})
+	res, err := m.Insert(1, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, res)
+	res, err = m.Insert(1, "dup")
+	assert.NoError(t, err)
+	assert.Equal(t, -1, res)
+	m.Insert(3, "3")
+	m.Insert(2, "2")
+	res, err = m.Delete(3)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, res)
+	assert.NoError(t, m.Close())
+
+	_, err = m.Insert(4, "4")
+	assert.ErrorIs(t, err, sortedmap.ErrDurableClosed)
+	_, err = m.Insert(1, "dup")
+	assert.ErrorIs(t, err, sortedmap.ErrDurableClosed)
+	_, err = m.Delete(3)
+	assert.ErrorIs(t, err, sortedmap.ErrDurableClosed)
+
+	m = openDurable(t, dir, sortedmap.DurableOptions{})
+	assert.Equal(t, []string{"1", "2"}, m.GetGreaterOrEqual(0))
+
+	assert.NoError(t, m.Clear())
+	m.Insert(5, "5")
+	assert.NoError(t, m.Close())
+
+	m = openDurable(t, dir, sortedmap.DurableOptions{})
+	assert.Equal(t, []string{"5"}, m.GetGreaterOrEqual(0))
+	assert.NoError(t, m.Close())
+}
+
+func TestDurableSortedMap_TornTail(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	m := openDurable(t, dir, sortedmap.DurableOptions{Sync: sortedmap.SyncNever})
+	m.Insert(1, "1")
+	m.Insert(2, "2")
+	assert.NoError(t, m.Close())
+
+	logPath := filepath.Join(dir, "wal")
+	info, err := os.Stat(logPath)
+	assert.NoError(t, err)
+	assert.NoError(t, os.Truncate(logPath, info.Size()-1))
+
+	m = openDurable(t, dir, sortedmap.DurableOptions{})
+	assert.Equal(t, []string{"1"}, m.GetGreaterOrEqual(0))
+	m.Insert(3, "3")
+	assert.NoError(t, m.Close())
+
+	m = openDurable(t, dir, sortedmap.DurableOptions{})
+	assert.Equal(t, []string{"1", "3"}, m.GetGreaterOrEqual(0))
+	assert.NoError(t, m.Close())
+}
+
+func TestDurableSortedMap_Checkpoint(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	m := openDurable(t, dir, sortedmap.DurableOptions{Sync: sortedmap.SyncInterval, CheckpointEvery: 3})
+	m.Insert(1, "1")
+	m.Insert(2, "2")
+	m.Insert(3, "3")
+
+	info, err := os.Stat(filepath.Join(dir, "wal"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), info.Size())
+
+	m.Delete(1)
+	assert.NoError(t, m.Checkpoint())
+	m.Insert(4, "4")
+	assert.NoError(t, m.Sync())
+	assert.NoError(t, m.Close())
+
+	m = openDurable(t, dir, sortedmap.DurableOptions{})
+	assert.Equal(t, []string{"2", "3", "4"}, m.GetGreaterOrEqual(0))
+	assert.Equal(t, 3, m.Size())
+	assert.NoError(t, m.Close())
+}
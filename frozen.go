@@ -0,0 +1,319 @@
+package sortedmap
+
+import (
+	"math/bits"
+	"runtime"
+
+	"golang.org/x/exp/constraints"
+)
+
+// eytzinger stores keys in the breadth-first order of the implicit binary
+// search tree, so the first levels of every search share a few cache lines
+// and the next node is always at 2k or 2k+1.
+type eytzinger[K constraints.Ordered] struct {
+	keys []K // keys[1:] in BFS order, keys[0] is unused
+	cmp  func(K, K) int
+}
+
+// prefetchLevels is how far ahead lowerBound loads the nodes of the descent:
+// the 16 descendants of k four levels down start at 16k and, for small keys,
+// share a cache line.
+const prefetchLevels = 4
+
+func newEytzinger[K constraints.Ordered](sorted []K, cmp func(K, K) int) eytzinger[K] {
+	e := eytzinger[K]{
+		keys: make([]K, len(sorted)+1),
+		cmp:  cmp,
+	}
+	e.fill(sorted, 0, 1)
+	return e
+}
+
+func (e *eytzinger[K]) fill(sorted []K, i int, k int) int {
+	if k < len(e.keys) {
+		i = e.fill(sorted, i, 2*k)
+		e.keys[k] = sorted[i]
+		i++
+		i = e.fill(sorted, i, 2*k+1)
+	}
+	return i
+}
+
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// lowerBound returns the node of the first key not less than key, or 0.
+// The descent has no data-dependent branch: each step only picks a child.
+// The unused load of the node prefetchLevels below k starts fetching its
+// cache line while the comparisons of the levels in between run.
+func (e *eytzinger[K]) lowerBound(key K) int {
+	var ahead K
+	k := 1
+	if e.cmp == nil {
+		for k < len(e.keys) {
+			if p := k << prefetchLevels; p < len(e.keys) {
+				ahead = e.keys[p]
+			}
+			k = 2*k + btoi(e.keys[k] < key)
+		}
+	} else {
+		for k < len(e.keys) {
+			if p := k << prefetchLevels; p < len(e.keys) {
+				ahead = e.keys[p]
+			}
+			k = 2*k + btoi(e.cmp(e.keys[k], key) < 0)
+		}
+	}
+	runtime.KeepAlive(ahead)
+	// Drop the trailing right turns and the final left one.
+	return k >> (bits.TrailingZeros(^uint(k)) + 1)
+}
+
+// rank returns the sorted position of node k, or len for node 0. In the
+// perfect tree of height h, the nodes of depth d are 2^(h-d) positions apart
+// starting at 2^(h-1-d)-1; the leaves missing from the last level are the
+// even positions from twice the number of leaves on.
+func (e *eytzinger[K]) rank(k int) int {
+	n := len(e.keys) - 1
+	if k == 0 {
+		return n
+	}
+	h := bits.Len(uint(n))
+	d := bits.Len(uint(k)) - 1
+	pos := (2*(k-1<<d)+1)<<(h-1-d) - 1
+	if leaves := n - (1<<(h-1) - 1); pos > 2*leaves {
+		pos -= (pos - 2*leaves + 1) / 2
+	}
+	return pos
+}
+
+// search works like layout.search on the sorted keys.
+func (e *eytzinger[K]) search(key K) (int, bool) {
+	k := e.lowerBound(key)
+	if k == 0 {
+		return e.rank(0), false
+	}
+	if e.cmp == nil {
+		return e.rank(k), !(key < e.keys[k])
+	}
+	return e.rank(k), e.cmp(key, e.keys[k]) == 0
+}
+
+func layoutCmp[K constraints.Ordered, V any](l *layout[K, V]) func(K, K) int {
+	if l == nil {
+		return nil
+	}
+	return l.cmp
+}
+
+// FrozenSortedMap is a read-only map built by Freeze. Lookups run on an
+// Eytzinger layout of the keys, which is faster than a binary search once
+// the keys no longer fit in cache.
+type FrozenSortedMap[K constraints.Ordered, V any] struct {
+	index  eytzinger[K]
+	keys   []K
+	values []V
+}
+
+func newFrozenSortedMap[K constraints.Ordered, V any](keys []K, values []V, cmp func(K, K) int) *FrozenSortedMap[K, V] {
+	return &FrozenSortedMap[K, V]{
+		index:  newEytzinger(keys, cmp),
+		keys:   append(make([]K, 0, len(keys)), keys...),
+		values: append(make([]V, 0, len(values)), values...),
+	}
+}
+
+func (s *NoLockSortedMap[K, V]) Freeze() *FrozenSortedMap[K, V] {
+	return newFrozenSortedMap(s.keys, s.values, layoutCmp(s.layout))
+}
+
+func (s *SortedMap[K, V]) Freeze() *FrozenSortedMap[K, V] {
+	s.m.RLock()
+	res := s.s.Freeze()
+	s.m.RUnlock()
+	return res
+}
+
+func (s *NoLockSortedMapCalc[K, V]) Freeze() *FrozenSortedMap[K, V] {
+	return newFrozenSortedMap(s.keys, s.values, layoutCmp(s.layout))
+}
+
+func (s *SortedMapCalc[K, V]) Freeze() *FrozenSortedMap[K, V] {
+	s.m.RLock()
+	res := s.s.Freeze()
+	s.m.RUnlock()
+	return res
+}
+
+func (s *FrozenSortedMap[K, V]) Size() int {
+	return len(s.values)
+}
+
+func (s *FrozenSortedMap[K, V]) Contains(key K) bool {
+	_, exists := s.index.search(key)
+	return exists
+}
+
+func (s *FrozenSortedMap[K, V]) Get(key K) (V, bool) {
+	pos, exists := s.index.search(key)
+	if !exists {
+		var zero V
+		return zero, false
+	}
+	return s.values[pos], true
+}
+
+// Floor returns the entry with the greatest key less than or equal to key.
+func (s *FrozenSortedMap[K, V]) Floor(key K) (K, V, bool) {
+	pos := s.GetIndexOfGreater(key)
+	if pos == 0 {
+		var zeroK K
+		var zeroV V
+		return zeroK, zeroV, false
+	}
+	return s.keys[pos-1], s.values[pos-1], true
+}
+
+// Ceiling returns the entry with the least key greater than or equal to key.
+func (s *FrozenSortedMap[K, V]) Ceiling(key K) (K, V, bool) {
+	pos := s.GetIndexOfGreaterOrEqual(key)
+	if pos == len(s.keys) {
+		var zeroK K
+		var zeroV V
+		return zeroK, zeroV, false
+	}
+	return s.keys[pos], s.values[pos], true
+}
+
+func (s *FrozenSortedMap[K, V]) GetIndexOfGreater(key K) int {
+	pos, exists := s.index.search(key)
+	if exists {
+		pos++
+	}
+	return pos
+}
+
+func (s *FrozenSortedMap[K, V]) GetIndexOfGreaterOrEqual(key K) int {
+	pos, _ := s.index.search(key)
+	return pos
+}
+
+func (s *FrozenSortedMap[K, V]) GetGreater(key K) []V {
+	return s.values[s.GetIndexOfGreater(key):]
+}
+
+func (s *FrozenSortedMap[K, V]) GetGreaterOrEqual(key K) []V {
+	return s.values[s.GetIndexOfGreaterOrEqual(key):]
+}
+
+func (s *FrozenSortedMap[K, V]) GetLess(key K) []V {
+	return s.values[:s.GetIndexOfGreaterOrEqual(key)]
+}
+
+func (s *FrozenSortedMap[K, V]) GetLessOrEqual(key K) []V {
+	return s.values[:s.GetIndexOfGreater(key)]
+}
+
+func (s *FrozenSortedMap[K, V]) GetByInclusiveRange(startKey K, endKey K) []V {
+	startPos := s.GetIndexOfGreaterOrEqual(startKey)
+	endPos := s.GetIndexOfGreater(endKey)
+	if startPos >= endPos {
+		return s.values[:0]
+	}
+	return s.values[startPos:endPos]
+}
+
+// FrozenSortedSet is the set counterpart of FrozenSortedMap.
+type FrozenSortedSet[K constraints.Ordered] struct {
+	index  eytzinger[K]
+	values []K
+}
+
+func (s *NoLockSortedSet[K]) Freeze() *FrozenSortedSet[K] {
+	return &FrozenSortedSet[K]{
+		index:  newEytzinger(s.values, layoutCmp(s.layout)),
+		values: append(make([]K, 0, len(s.values)), s.values...),
+	}
+}
+
+func (s *SortedSet[K]) Freeze() *FrozenSortedSet[K] {
+	s.m.RLock()
+	res := s.s.Freeze()
+	s.m.RUnlock()
+	return res
+}
+
+func (s *FrozenSortedSet[K]) Size() int {
+	return len(s.values)
+}
+
+func (s *FrozenSortedSet[K]) Capacity() int {
+	return len(s.values)
+}
+
+func (s *FrozenSortedSet[K]) Contains(value K) bool {
+	_, exists := s.index.search(value)
+	return exists
+}
+
+// Floor returns the greatest value less than or equal to value.
+func (s *FrozenSortedSet[K]) Floor(value K) (K, bool) {
+	pos := s.GetIndexOfGreater(value)
+	if pos == 0 {
+		var zero K
+		return zero, false
+	}
+	return s.values[pos-1], true
+}
+
+// Ceiling returns the least value greater than or equal to value.
+func (s *FrozenSortedSet[K]) Ceiling(value K) (K, bool) {
+	pos := s.GetIndexOfGreaterOrEqual(value)
+	if pos == len(s.values) {
+		var zero K
+		return zero, false
+	}
+	return s.values[pos], true
+}
+
+func (s *FrozenSortedSet[K]) GetIndexOfGreater(value K) int {
+	pos, exists := s.index.search(value)
+	if exists {
+		pos++
+	}
+	return pos
+}
+
+func (s *FrozenSortedSet[K]) GetIndexOfGreaterOrEqual(value K) int {
+	pos, _ := s.index.search(value)
+	return pos
+}
+
+func (s *FrozenSortedSet[K]) GetGreater(value K) []K {
+	return s.values[s.GetIndexOfGreater(value):]
+}
+
+func (s *FrozenSortedSet[K]) GetGreaterOrEqual(value K) []K {
+	return s.values[s.GetIndexOfGreaterOrEqual(value):]
+}
+
+func (s *FrozenSortedSet[K]) GetLess(value K) []K {
+	return s.values[:s.GetIndexOfGreaterOrEqual(value)]
+}
+
+func (s *FrozenSortedSet[K]) GetLessOrEqual(value K) []K {
+	return s.values[:s.GetIndexOfGreater(value)]
+}
+
+func (s *FrozenSortedSet[K]) GetByInclusiveRange(startValue K, endValue K) []K {
+	startPos := s.GetIndexOfGreaterOrEqual(startValue)
+	endPos := s.GetIndexOfGreater(endValue)
+	if startPos >= endPos {
+		return s.values[:0]
+	}
+	return s.values[startPos:endPos]
+}
@@ -0,0 +1,94 @@
+package sortedmap_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/sapphi-red/sortedmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoLockSortedMap_Freeze(t *testing.T) {
+	t.Parallel()
+
+	// every size up to a few full tree levels, so that all shapes of the
+	// last level are covered
+	for size := 0; size < 70; size++ {
+		m := sortedmap.NewNoLockSortedMap[int, string](size)
+		for i := 0; i < size; i++ {
+			m.Insert(i*2, strconv.Itoa(i*2))
+		}
+		f := m.Freeze()
+		assert.Equal(t, size, f.Size())
+
+		for key := -1; key <= size*2; key++ {
+			assert.Equal(t, m.Contains(key), f.Contains(key))
+			assert.Equal(t, m.GetIndexOfGreater(key), f.GetIndexOfGreater(key))
+			assert.Equal(t, m.GetIndexOfGreaterOrEqual(key), f.GetIndexOfGreaterOrEqual(key))
+			assert.Equal(t, m.GetLess(key), f.GetLess(key))
+			assert.Equal(t, m.GetGreaterOrEqual(key), f.GetGreaterOrEqual(key))
+			assert.Equal(t, m.GetByInclusiveRange(key, key+4), f.GetByInclusiveRange(key, key+4))
+		}
+	}
+}
+
+func TestFrozenSortedMap_FloorCeiling(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMap[int, string](3)
+	m.InsertAll([]int{10, 20, 30}, []string{"a", "b", "c"})
+	f := m.Freeze()
+	m.Clear()
+
+	key, value, ok := f.Floor(25)
+	assert.True(t, ok)
+	assert.Equal(t, 20, key)
+	assert.Equal(t, "b", value)
+	_, _, ok = f.Floor(9)
+	assert.False(t, ok)
+
+	key, value, ok = f.Ceiling(20)
+	assert.True(t, ok)
+	assert.Equal(t, 20, key)
+	assert.Equal(t, "b", value)
+	_, _, ok = f.Ceiling(31)
+	assert.False(t, ok)
+
+	value, ok = f.Get(30)
+	assert.True(t, ok)
+	assert.Equal(t, "c", value)
+	assert.Empty(t, f.GetByInclusiveRange(30, 10))
+}
+
+func TestSortedSet_Freeze(t *testing.T) {
+	t.Parallel()
+
+	set := sortedmap.NewSortedSetWithOptions[int](sortedmap.WithDescending())
+	set.InsertAll([]int{1, 3, 5, 7})
+	f := set.Freeze()
+
+	assert.True(t, f.Contains(5))
+	assert.False(t, f.Contains(4))
+	assert.Equal(t, []int{7, 5}, f.GetLess(4))
+	assert.Equal(t, []int{5, 3}, f.GetByInclusiveRange(6, 2))
+
+	value, ok := f.Floor(4)
+	assert.True(t, ok)
+	assert.Equal(t, 5, value)
+	value, ok = f.Ceiling(4)
+	assert.True(t, ok)
+	assert.Equal(t, 3, value)
+	_, ok = f.Ceiling(0)
+	assert.False(t, ok)
+}
+
+func TestSortedMapCalc_Freeze(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMapCalc(5, safeAtoi)
+	m.InsertAll([]string{"3", "1", "2"})
+	f := m.Freeze()
+
+	assert.Equal(t, []string{"2", "3"}, f.GetGreater(1))
+	assert.True(t, f.Contains(3))
+}
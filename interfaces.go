@@ -0,0 +1,130 @@
+package sortedmap
+
+import "golang.org/x/exp/constraints"
+
+type ReadOnlySortedSet[K constraints.Ordered] interface {
+	Size() int
+	Capacity() int
+	Contains(value K) bool
+	GetIndexOfGreater(value K) int
+	GetIndexOfGreaterOrEqual(value K) int
+	GetGreater(value K) []K
+	GetGreaterOrEqual(value K) []K
+	GetLess(value K) []K
+	GetLessOrEqual(value K) []K
+	GetByInclusiveRange(startValue K, endValue K) []K
+}
+
+type SortedSetI[K constraints.Ordered] interface {
+	ReadOnlySortedSet[K]
+	ExtendCapacityTo(newCap int)
+	Clear()
+	Insert(value K) int
+	InsertWithAfterHint(value K, afterIndex int) int
+	Delete(value K) int
+	DeleteWithAfterHint(value K, afterIndex int) int
+	InsertAll(values []K)
+	InsertAllOrdered(values []K)
+	DeleteAll(values []K)
+	DeleteAllOrdered(values []K)
+}
+
+// ReadOnlySortedMap is implemented by every map of this package, including
+// the calculated-key, persistent, RCU, durable and memory-mapped ones, except
+// ShardedSortedMap, whose shards do not share a global index.
+type ReadOnlySortedMap[K constraints.Ordered, V any] interface {
+	Size() int
+	Contains(key K) bool
+	GetIndexOfGreater(key K) int
+	GetIndexOfGreaterOrEqual(key K) int
+	GetGreater(key K) []V
+	GetGreaterOrEqual(key K) []V
+	GetLess(key K) []V
+	GetLessOrEqual(key K) []V
+	GetByInclusiveRange(startKey K, endKey K) []V
+}
+
+type SortedMapI[K constraints.Ordered, V any] interface {
+	ReadOnlySortedMap[K, V]
+	Capacity() int
+	ExtendCapacityTo(newCap int)
+	Clear()
+	Insert(key K, value V) int
+	InsertWithAfterHint(key K, value V, afterIndex int) int
+	Delete(key K) int
+	DeleteWithAfterHint(key K, afterIndex int) int
+	InsertAll(keys []K, values []V)
+	InsertAllByMap(m map[K]V)
+	InsertAllOrdered(keys []K, values []V)
+	DeleteAll(keys []K)
+	DeleteAllOrdered(keys []K)
+}
+
+var (
+	_ SortedSetI[int] = (*NoLockSortedSet[int])(nil)
+	_ SortedSetI[int] = (*SortedSet[int])(nil)
+	_ SortedSetI[int] = (*ChunkedSortedSet[int])(nil)
+
+	_ SortedMapI[int, int] = (*NoLockSortedMap[int, int])(nil)
+	_ SortedMapI[int, int] = (*SortedMap[int, int])(nil)
+	_ SortedMapI[int, int] = (*ChunkedSortedMap[int, int])(nil)
+
+	_ ReadOnlySortedSet[int] = (*FrozenSortedSet[int])(nil)
+
+	_ ReadOnlySortedMap[int, int]     = (*NoLockSortedMapCalc[int, int])(nil)
+	_ ReadOnlySortedMap[int, int]     = (*SortedMapCalc[int, int])(nil)
+	_ ReadOnlySortedMap[int, int]     = (*PersistentSortedMap[int, int])(nil)
+	_ ReadOnlySortedMap[int, int]     = (*RCUSortedMap[int, int])(nil)
+	_ ReadOnlySortedMap[int, int]     = (*DurableSortedMap[int, int])(nil)
+	_ ReadOnlySortedMap[int, int]     = (*FrozenSortedMap[int, int])(nil)
+	_ ReadOnlySortedMap[int64, int64] = (*MappedSortedMap[int64, int64])(nil)
+)
+
+// WrapSortedSet returns a SortedSet guarding s, which keeps collecting the
+// stats of s if they were enabled. s must not be used directly afterwards.
+func WrapSortedSet[K constraints.Ordered](s *NoLockSortedSet[K]) *SortedSet[K] {
+	w := &SortedSet[K]{s: *s}
+	if s.stats != nil {
+		w.m.stats.Store(s.stats)
+	}
+	return w
+}
+
+// Unwrap returns the set behind the lock. It may only be used while nothing
+// else uses s, such as during setup or after shutdown.
+func (s *SortedSet[K]) Unwrap() *NoLockSortedSet[K] {
+	return &s.s
+}
+
+// WrapSortedMap returns a SortedMap guarding s, which keeps collecting the
+// stats of s if they were enabled. s must not be used directly afterwards.
+func WrapSortedMap[K constraints.Ordered, V any](s *NoLockSortedMap[K, V]) *SortedMap[K, V] {
+	w := &SortedMap[K, V]{s: *s}
+	if s.stats != nil {
+		w.m.stats.Store(s.stats)
+	}
+	return w
+}
+
+// Unwrap returns the map behind the lock. It may only be used while nothing
+// else uses s, such as during setup or after shutdown.
+func (s *SortedMap[K, V]) Unwrap() *NoLockSortedMap[K, V] {
+	return &s.s
+}
+
+// WrapSortedMapCalc returns a SortedMapCalc guarding s, which keeps
+// collecting the stats of s if they were enabled. s must not be used directly
+// afterwards.
+func WrapSortedMapCalc[K constraints.Ordered, V any](s *NoLockSortedMapCalc[K, V]) *SortedMapCalc[K, V] {
+	w := &SortedMapCalc[K, V]{s: *s}
+	if s.stats != nil {
+		w.m.stats.Store(s.stats)
+	}
+	return w
+}
+
+// Unwrap returns the map behind the lock. It may only be used while nothing
+// else uses s, such as during setup or after shutdown.
+func (s *SortedMapCalc[K, V]) Unwrap() *NoLockSortedMapCalc[K, V] {
+	return &s.s
+}
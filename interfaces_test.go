@@ -0,0 +1,93 @@
+package sortedmap_test
+
+import (
+	"testing"
+
+	"github.com/sapphi-red/sortedmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func fillSet(s sortedmap.SortedSetI[int]) {
+	s.InsertAll([]int{3, 1, 2})
+	s.Delete(2)
+}
+
+func sumRange(m sortedmap.ReadOnlySortedMap[int, string], lo int, hi int) string {
+	res := ""
+	for _, v := range m.GetByInclusiveRange(lo, hi) {
+		res += v
+	}
+	return res
+}
+
+func TestInterfaces(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []sortedmap.SortedSetI[int]{
+		sortedmap.NewNoLockSortedSet[int](5),
+		sortedmap.NewSortedSet[int](5),
+	} {
+		fillSet(s)
+		assert.Equal(t, []int{1, 3}, s.GetGreaterOrEqual(0))
+	}
+
+	m := sortedmap.NewSortedMap[int, string](5)
+	m.InsertAll([]int{1, 2, 3}, []string{"1", "2", "3"})
+	calc := sortedmap.NewNoLockSortedMapCalc(5, safeAtoi)
+	calc.InsertAll([]string{"1", "2", "3"})
+	p := sortedmap.NewPersistentSortedMap[int, string]()
+	p, _ = p.Insert(2, "2")
+
+	for _, ro := range []sortedmap.ReadOnlySortedMap[int, string]{m, calc, p} {
+		assert.Equal(t, "2", sumRange(ro, 2, 2))
+	}
+}
+
+func TestWrap(t *testing.T) {
+	t.Parallel()
+
+	nolock := sortedmap.NewNoLockSortedMap[int, string](5)
+	nolock.Insert(1, "1")
+	m := sortedmap.WrapSortedMap(nolock)
+	m.Insert(2, "2")
+	assert.Equal(t, []string{"1", "2"}, m.Unwrap().GetGreaterOrEqual(0))
+
+	set := sortedmap.WrapSortedSet(sortedmap.NewNoLockSortedSet[int](5))
+	set.Insert(1)
+	assert.Equal(t, 1, set.Unwrap().Size())
+
+	calc := sortedmap.WrapSortedMapCalc(sortedmap.NewNoLockSortedMapCalc(5, safeAtoi))
+	calc.Insert("3")
+	assert.True(t, calc.Unwrap().Contains(3))
+}
+
+func TestWrap_Stats(t *testing.T) {
+	t.Parallel()
+
+	nolock := sortedmap.NewNoLockSortedMap[int, string](5)
+	nolock.EnableStats()
+	nolock.Insert(1, "1")
+	m := sortedmap.WrapSortedMap(nolock)
+	m.Insert(2, "2")
+	m.Contains(3)
+
+	stats := m.Stats()
+	assert.Equal(t, uint64(2), stats.Inserts)
+	assert.Equal(t, uint64(1), stats.Misses)
+	assert.Equal(t, uint64(2), stats.LockAcquisitions)
+
+	set := sortedmap.NewNoLockSortedSet[int](5)
+	set.EnableStats()
+	wrappedSet := sortedmap.WrapSortedSet(set)
+	wrappedSet.Insert(1)
+	assert.Equal(t, uint64(1), wrappedSet.Stats().Inserts)
+	assert.Equal(t, uint64(1), wrappedSet.Stats().LockAcquisitions)
+
+	calc := sortedmap.NewNoLockSortedMapCalc(5, safeAtoi)
+	calc.EnableStats()
+	wrappedCalc := sortedmap.WrapSortedMapCalc(calc)
+	wrappedCalc.Insert("1")
+	assert.Equal(t, uint64(1), wrappedCalc.Stats().Inserts)
+
+	assert.Equal(t, sortedmap.Stats{}, sortedmap.WrapSortedSet(sortedmap.NewNoLockSortedSet[int](5)).Stats())
+}
@@ -0,0 +1,219 @@
+package sortedmap
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"unsafe"
+)
+
+type FixedWidth interface {
+	~int8 | ~int16 | ~int32 | ~int64 |
+		~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// The header holds the magic, the version, the sizes of K and V, the byte
+// order mark, the entry count and the reflect.Kind of K and V.
+const (
+	mappedHeaderSize = 40
+	mappedVersion    = 2
+	mappedByteOrder  = 0x01020304
+)
+
+var mappedMagic = [8]byte{'S', 'R', 'T', 'D', 'M', 'M', 'A', 'P'}
+
+var ErrMappedFormat = errors.New("sortedmap: invalid mapped sorted map file")
+
+// MappedSortedMap is a read-only map whose keys and values are used directly
+// from a memory-mapped file written by WriteMappedSortedMap.
+// The file is stored in native byte order and is not portable across
+// architectures with a different endianness. Opening it checks that K and V
+// have the kinds it was written with, but not that the keys are sorted,
+// which would read the whole file up front.
+type MappedSortedMap[K FixedWidth, V FixedWidth] struct {
+	s    NoLockSortedMap[K, V]
+	data []byte
+}
+
+func align8(n int) int {
+	return (n + 7) &^ 7
+}
+
+func rawBytes[T FixedWidth](s []T) []byte {
+	if len(s) == 0 {
+		return nil
+	}
+	var zero T
+	return unsafe.Slice((*byte)(unsafe.Pointer(&s[0])), len(s)*int(unsafe.Sizeof(zero)))
+}
+
+func WriteMappedSortedMap[K FixedWidth, V FixedWidth](path string, m *NoLockSortedMap[K, V]) error {
+	var zeroK K
+	var zeroV V
+	keySize := int(unsafe.Sizeof(zeroK))
+	valueSize := int(unsafe.Sizeof(zeroV))
+
+	var header [mappedHeaderSize]byte
+	copy(header[:], mappedMagic[:])
+	byteOrder := nativeEndian()
+	byteOrder.PutUint32(header[8:], mappedVersion)
+	byteOrder.PutUint32(header[12:], uint32(keySize))
+	byteOrder.PutUint32(header[16:], uint32(valueSize))
+	byteOrder.PutUint32(header[20:], mappedByteOrder)
+	byteOrder.PutUint64(header[24:], uint64(m.Size()))
+	byteOrder.PutUint32(header[32:], uint32(reflect.TypeOf(zeroK).Kind()))
+	byteOrder.PutUint32(header[36:], uint32(reflect.TypeOf(zeroV).Kind()))
+
+	tmpPath := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath)
+
+	w := bufio.NewWriter(f)
+	w.Write(header[:])
+	keyBytes := rawBytes(m.keys)
+	w.Write(keyBytes)
+	w.Write(make([]byte, align8(len(keyBytes))-len(keyBytes)))
+	if _, err := w.Write(rawBytes(m.values)); err != nil {
+		f.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+func OpenMappedSortedMap[K FixedWidth, V FixedWidth](path string) (*MappedSortedMap[K, V], error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size()
+	if size < mappedHeaderSize || int64(int(size)) != size {
+		return nil, ErrMappedFormat
+	}
+
+	data, err := mapFile(f, int(size))
+	if err != nil {
+		return nil, err
+	}
+	m, err := newMappedSortedMap[K, V](data)
+	if err != nil {
+		unmapFile(data)
+		return nil, err
+	}
+	return m, nil
+}
+
+func newMappedSortedMap[K FixedWidth, V FixedWidth](data []byte) (*MappedSortedMap[K, V], error) {
+	var zeroK K
+	var zeroV V
+	keySize := int(unsafe.Sizeof(zeroK))
+	valueSize := int(unsafe.Sizeof(zeroV))
+
+	byteOrder := nativeEndian()
+	if [8]byte{data[0], data[1], data[2], data[3], data[4], data[5], data[6], data[7]} != mappedMagic ||
+		byteOrder.Uint32(data[8:]) != mappedVersion ||
+		byteOrder.Uint32(data[12:]) != uint32(keySize) ||
+		byteOrder.Uint32(data[16:]) != uint32(valueSize) ||
+		byteOrder.Uint32(data[20:]) != mappedByteOrder ||
+		byteOrder.Uint32(data[32:]) != uint32(reflect.TypeOf(zeroK).Kind()) ||
+		byteOrder.Uint32(data[36:]) != uint32(reflect.TypeOf(zeroV).Kind()) {
+		return nil, ErrMappedFormat
+	}
+
+	count := byteOrder.Uint64(data[24:])
+	maxCount := uint64(len(data)-mappedHeaderSize) / uint64(keySize+valueSize)
+	if count > maxCount {
+		return nil, ErrMappedFormat
+	}
+	n := int(count)
+	keysEnd := mappedHeaderSize + align8(n*keySize)
+	if keysEnd+n*valueSize > len(data) {
+		return nil, ErrMappedFormat
+	}
+
+	m := &MappedSortedMap[K, V]{data: data}
+	if n == 0 {
+		m.s.keys = []K{}
+		m.s.values = []V{}
+		return m, nil
+	}
+	m.s.keys = unsafe.Slice((*K)(unsafe.Pointer(&data[mappedHeaderSize])), n)
+	m.s.values = unsafe.Slice((*V)(unsafe.Pointer(&data[keysEnd])), n)
+	return m, nil
+}
+
+func nativeEndian() binary.ByteOrder {
+	x := uint16(1)
+	if *(*byte)(unsafe.Pointer(&x)) == 1 {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}
+
+// Close releases the mapping. Slices previously returned by the query
+// methods must not be used afterwards.
+func (s *MappedSortedMap[K, V]) Close() error {
+	data := s.data
+	s.data = nil
+	s.s.keys = nil
+	s.s.values = nil
+	if data == nil {
+		return nil
+	}
+	return unmapFile(data)
+}
+
+func (s *MappedSortedMap[K, V]) Size() int {
+	return s.s.Size()
+}
+
+func (s *MappedSortedMap[K, V]) Contains(key K) bool {
+	return s.s.Contains(key)
+}
+
+func (s *MappedSortedMap[K, V]) GetIndexOfGreater(key K) int {
+	return s.s.GetIndexOfGreater(key)
+}
+func (s *MappedSortedMap[K, V]) GetIndexOfGreaterOrEqual(key K) int {
+	return s.s.GetIndexOfGreaterOrEqual(key)
+}
+
+func (s *MappedSortedMap[K, V]) GetGreater(key K) []V {
+	return s.s.GetGreater(key)
+}
+func (s *MappedSortedMap[K, V]) GetGreaterOrEqual(key K) []V {
+	return s.s.GetGreaterOrEqual(key)
+}
+func (s *MappedSortedMap[K, V]) GetLess(key K) []V {
+	return s.s.GetLess(key)
+}
+func (s *MappedSortedMap[K, V]) GetLessOrEqual(key K) []V {
+	return s.s.GetLessOrEqual(key)
+}
+
+func (s *MappedSortedMap[K, V]) GetByInclusiveRange(startKey K, endKey K) []V {
+	return s.s.GetByInclusiveRange(startKey, endKey)
+}
@@ -0,0 +1,71 @@
+package sortedmap_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sapphi-red/sortedmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMappedSortedMap(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewNoLockSortedMap[int64, float32](5)
+	m.Insert(30, 3)
+	m.Insert(10, 1)
+	m.Insert(20, 2)
+
+	path := filepath.Join(t.TempDir(), "table")
+	assert.NoError(t, sortedmap.WriteMappedSortedMap(path, m))
+
+	mapped, err := sortedmap.OpenMappedSortedMap[int64, float32](path)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, mapped.Size())
+	assert.Equal(t, true, mapped.Contains(20))
+	assert.Equal(t, false, mapped.Contains(25))
+	assert.Equal(t, 2, mapped.GetIndexOfGreater(20))
+	assert.Equal(t, 1, mapped.GetIndexOfGreaterOrEqual(20))
+	assert.Equal(t, []float32{2, 3}, mapped.GetGreaterOrEqual(20))
+	assert.Equal(t, []float32{1}, mapped.GetLess(20))
+	assert.Equal(t, []float32{1, 2}, mapped.GetByInclusiveRange(0, 25))
+	assert.NoError(t, mapped.Close())
+}
+
+func TestMappedSortedMap_Empty(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "table")
+	assert.NoError(t, sortedmap.WriteMappedSortedMap(path, sortedmap.NewNoLockSortedMap[int32, int8](0)))
+
+	mapped, err := sortedmap.OpenMappedSortedMap[int32, int8](path)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, mapped.Size())
+	assert.Equal(t, []int8{}, mapped.GetGreater(0))
+	assert.NoError(t, mapped.Close())
+}
+
+func TestMappedSortedMap_Invalid(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewNoLockSortedMap[int64, int64](1)
+	m.Insert(1, 1)
+	path := filepath.Join(t.TempDir(), "table")
+	assert.NoError(t, sortedmap.WriteMappedSortedMap(path, m))
+
+	_, err := sortedmap.OpenMappedSortedMap[int32, int64](path)
+	assert.ErrorIs(t, err, sortedmap.ErrMappedFormat)
+	_, err = sortedmap.OpenMappedSortedMap[uint64, int64](path)
+	assert.ErrorIs(t, err, sortedmap.ErrMappedFormat)
+	_, err = sortedmap.OpenMappedSortedMap[float64, int64](path)
+	assert.ErrorIs(t, err, sortedmap.ErrMappedFormat)
+	_, err = sortedmap.OpenMappedSortedMap[int64, float64](path)
+	assert.ErrorIs(t, err, sortedmap.ErrMappedFormat)
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.NoError(t, os.Truncate(path, info.Size()-1))
+	_, err = sortedmap.OpenMappedSortedMap[int64, int64](path)
+	assert.ErrorIs(t, err, sortedmap.ErrMappedFormat)
+}
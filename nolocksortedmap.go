@@ -2,12 +2,14 @@ package sortedmap
 
 import (
 	"golang.org/x/exp/constraints"
-	"golang.org/x/exp/slices"
 )
 
 type NoLockSortedMap[K constraints.Ordered, V any] struct {
 	keys   []K
 	values []V
+	hook   func(ChangeEvent[K, V])
+	stats  *statsCounters
+	layout *layout[K, V]
 }
 
 func NewNoLockSortedMap[K constraints.Ordered, V any](capacity int) *NoLockSortedMap[K, V] {
@@ -17,6 +19,15 @@ func NewNoLockSortedMap[K constraints.Ordered, V any](capacity int) *NoLockSorte
 	}
 }
 
+func NewNoLockSortedMapWithOptions[K constraints.Ordered, V any](opts ...Option) *NoLockSortedMap[K, V] {
+	l, capacity := newLayout[K, V](opts)
+	return &NoLockSortedMap[K, V]{
+		keys:   l.makeKeys(capacity),
+		values: l.makeValues(capacity),
+		layout: l,
+	}
+}
+
 func (s *NoLockSortedMap[K, V]) Size() int {
 	return len(s.values)
 }
@@ -26,26 +37,52 @@ func (s *NoLockSortedMap[K, V]) Capacity() int {
 }
 
 func (s *NoLockSortedMap[K, V]) ExtendCapacityTo(newCap int) {
+	newCap = s.layout.limit(newCap)
 	if s.Capacity() < newCap {
-		s.keys = append(make([]K, 0, newCap), s.keys...)
-		s.values = append(make([]V, 0, newCap), s.values...)
+		s.keys = append(s.layout.makeKeys(newCap), s.keys...)
+		s.values = append(s.layout.makeValues(newCap), s.values...)
+		s.stats.resized()
 	}
 }
 
 func (s *NoLockSortedMap[K, V]) Clear() {
+	if s.hook != nil {
+		for i := range s.keys {
+			s.hook(ChangeEvent[K, V]{Kind: ChangeDelete, Key: s.keys[i], Value: s.values[i]})
+		}
+	}
+	s.stats.deleted(len(s.values))
 	s.keys = s.keys[:0]
 	s.values = s.values[:0]
 }
 
+func (s *NoLockSortedMap[K, V]) clone(extraCapacity int) *NoLockSortedMap[K, V] {
+	return &NoLockSortedMap[K, V]{
+		keys:   append(s.layout.makeKeys(len(s.keys)+extraCapacity), s.keys...),
+		values: append(s.layout.makeValues(len(s.values)+extraCapacity), s.values...),
+		stats:  s.stats,
+		layout: s.layout,
+	}
+}
+
 func (s *NoLockSortedMap[K, V]) Insert(key K, value V) int {
-	pos, exists := slices.BinarySearch(s.keys, key)
+	pos, _ := s.InsertChecked(key, value)
+	return pos
+}
+
+// InsertChecked is like Insert, but returns ErrMaxCapacity when the key is
+// new and the map already holds WithMaxCapacity entries.
+func (s *NoLockSortedMap[K, V]) InsertChecked(key K, value V) (int, error) {
+	pos, exists := s.layout.search(s.keys, key)
 	if exists {
-		return -1
+		s.stats.duplicate()
+		return -1, nil
 	}
 
-	s.keys =  insertAt(s.keys, pos, key)
-	s.values =  insertAt(s.values, pos, value)
-	return pos
+	if !s.insertAtPos(pos, key, value) {
+		return -1, ErrMaxCapacity
+	}
+	return pos, nil
 }
 
 // TODO
@@ -55,25 +92,26 @@ func (s *NoLockSortedMap[K, V]) Insert(key K, value V) int {
 
 func (s *NoLockSortedMap[K, V]) InsertWithAfterHint(key K, value V, afterIndex int) int {
 	partialKeys := s.keys[afterIndex:]
-	pos, exists := slices.BinarySearch(partialKeys, key)
+	pos, exists := s.layout.search(partialKeys, key)
 	if exists {
+		s.stats.duplicate()
 		return -1
 	}
 
 	actualPos := afterIndex + pos
-	s.keys =  insertAt(s.keys, actualPos, key)
-	s.values =  insertAt(s.values, actualPos, value)
+	if !s.insertAtPos(actualPos, key, value) {
+		return -1
+	}
 	return actualPos
 }
 
 func (s *NoLockSortedMap[K, V]) Delete(key K) int {
-	pos, exists := slices.BinarySearch(s.keys, key)
+	pos, exists := s.layout.search(s.keys, key)
 	if !exists {
 		return -1
 	}
 
-	s.keys = deleteAt(s.keys, pos)
-	s.values = deleteAt(s.values, pos)
+	s.deleteAtPos(pos)
 	return pos
 }
 
@@ -84,17 +122,106 @@ func (s *NoLockSortedMap[K, V]) Delete(key K) int {
 
 func (s *NoLockSortedMap[K, V]) DeleteWithAfterHint(key K, afterIndex int) int {
 	partialKeys := s.keys[afterIndex:]
-	pos, exists := slices.BinarySearch(partialKeys, key)
+	pos, exists := s.layout.search(partialKeys, key)
 	if !exists {
 		return -1
 	}
 
 	actualPos := afterIndex + pos
-	s.keys =  deleteAt(s.keys, actualPos)
-	s.values =  deleteAt(s.values, actualPos)
+	s.deleteAtPos(actualPos)
 	return actualPos
 }
 
+func (s *NoLockSortedMap[K, V]) replaceAll(keys []K, values []V) {
+	if s.hook != nil {
+		s.Clear()
+		for i := range keys {
+			s.hook(ChangeEvent[K, V]{Kind: ChangeInsert, Index: i, Key: keys[i], Value: values[i]})
+		}
+	}
+	s.keys = keys
+	s.values = values
+}
+
+// reserve makes room for n more entries following the growth options. It
+// returns false when they would exceed the maximum capacity.
+func (s *NoLockSortedMap[K, V]) reserve(n int) bool {
+	need := len(s.values) + n
+	if need <= cap(s.keys) && need <= cap(s.values) {
+		return true
+	}
+	newCap, ok := s.layout.grow(cap(s.values), need)
+	if ok && newCap >= 0 {
+		s.keys = append(s.layout.makeKeys(newCap), s.keys...)
+		s.values = append(s.layout.makeValues(newCap), s.values...)
+	}
+	return ok
+}
+
+func (s *NoLockSortedMap[K, V]) insertAtPos(pos int, key K, value V) bool {
+	oldCap := cap(s.values)
+	if !s.reserve(1) {
+		s.stats.capacityRejected()
+		return false
+	}
+	s.keys = insertAt(s.keys, pos, key)
+	s.values = insertAt(s.values, pos, value)
+	s.stats.inserted(oldCap, cap(s.values))
+	if s.hook != nil {
+		s.hook(ChangeEvent[K, V]{Kind: ChangeInsert, Index: pos, Key: key, Value: value})
+	}
+	return true
+}
+
+func (s *NoLockSortedMap[K, V]) deleteAtPos(pos int) {
+	if s.hook != nil {
+		s.hook(ChangeEvent[K, V]{Kind: ChangeDelete, Index: pos, Key: s.keys[pos], Value: s.values[pos]})
+	}
+	s.keys = deleteAt(s.keys, pos)
+	s.values = deleteAt(s.values, pos)
+	s.stats.deleted(1)
+}
+
+func (s *NoLockSortedMap[K, V]) updateAt(pos int, value V) {
+	if s.hook != nil {
+		s.hook(ChangeEvent[K, V]{Kind: ChangeUpdate, Index: pos, Key: s.keys[pos], Value: value, OldValue: s.values[pos]})
+	}
+	s.values[pos] = value
+}
+
+// set inserts the entry or overwrites the value of an existing key.
+// It returns -1 when a new key does not fit in the maximum capacity.
+func (s *NoLockSortedMap[K, V]) set(key K, value V) int {
+	pos, exists := s.layout.search(s.keys, key)
+	if exists {
+		s.updateAt(pos, value)
+		return pos
+	}
+
+	if !s.insertAtPos(pos, key, value) {
+		return -1
+	}
+	return pos
+}
+
+func (s *NoLockSortedMap[K, V]) deleteRange(startKey K, endKey K) {
+	s.deleteBetween(s.GetIndexOfGreaterOrEqual(startKey), s.GetIndexOfGreater(endKey))
+}
+
+// deleteBetween deletes the entries in [startPos, endPos).
+func (s *NoLockSortedMap[K, V]) deleteBetween(startPos int, endPos int) {
+	if startPos < endPos {
+		if s.hook != nil {
+			for i := startPos; i < endPos; i++ {
+				s.hook(ChangeEvent[K, V]{Kind: ChangeDelete, Index: startPos, Key: s.keys[i], Value: s.values[i]})
+			}
+		}
+		s.keys = append(s.keys[:startPos], s.keys[endPos:]...)
+		s.values = append(s.values[:startPos], s.values[endPos:]...)
+		s.stats.deleted(endPos - startPos)
+	}
+}
+
 func (s *NoLockSortedMap[K, V]) InsertAll(keys []K, values []V) {
 	s.ExtendCapacityTo(s.Size() + len(values))
 
@@ -116,8 +243,24 @@ func (s *NoLockSortedMap[K, V]) InsertAllOrdered(keys []K, values []V) {
 
 	hint := 0
 	for i := range keys {
-		hint = s.InsertWithAfterHint(keys[i], values[i], hint)
+		if pos := s.InsertWithAfterHint(keys[i], values[i], hint); pos >= 0 {
+			hint = pos
+		}
+	}
+}
+
+// InsertAllChecked is like InsertAll, but returns ErrMaxCapacity when some
+// of the entries did not fit.
+func (s *NoLockSortedMap[K, V]) InsertAllChecked(keys []K, values []V) error {
+	s.ExtendCapacityTo(s.Size() + len(values))
+
+	var err error
+	for i := range keys {
+		if _, e := s.InsertChecked(keys[i], values[i]); e != nil {
+			err = e
+		}
 	}
+	return err
 }
 
 func (s *NoLockSortedMap[K, V]) DeleteAll(keys []K) {
@@ -138,41 +281,52 @@ func (s *NoLockSortedMap[K, V]) DeleteAllOrdered(keys []K) {
 }
 
 func (s *NoLockSortedMap[K, V]) Contains(key K) bool {
-	_, exists := slices.BinarySearch(s.keys, key)
+	_, exists := s.layout.search(s.keys, key)
+	s.stats.lookup(exists)
 	return exists
 }
 
 func (s *NoLockSortedMap[K, V]) GetIndexOfGreater(key K) int {
-	pos, exists := slices.BinarySearch(s.keys, key)
+	pos, exists := s.layout.search(s.keys, key)
 	if exists {
 		pos++ // does not include multiple same values
 	}
 	return pos
 }
 func (s *NoLockSortedMap[K, V]) GetIndexOfGreaterOrEqual(key K) int {
-	pos, _ := slices.BinarySearch(s.keys, key)
+	pos, _ := s.layout.search(s.keys, key)
 	return pos
 }
 
 func (s *NoLockSortedMap[K, V]) GetGreater(key K) []V {
 	pos := s.GetIndexOfGreater(key)
-	return s.values[pos:]
+	res := s.values[pos:]
+	s.stats.rangeSize(len(res))
+	return res
 }
 func (s *NoLockSortedMap[K, V]) GetGreaterOrEqual(key K) []V {
 	pos := s.GetIndexOfGreaterOrEqual(key)
-	return s.values[pos:]
+	res := s.values[pos:]
+	s.stats.rangeSize(len(res))
+	return res
 }
 func (s *NoLockSortedMap[K, V]) GetLess(key K) []V {
 	pos := s.GetIndexOfGreaterOrEqual(key)
-	return s.values[:pos]
+	res := s.values[:pos]
+	s.stats.rangeSize(len(res))
+	return res
 }
 func (s *NoLockSortedMap[K, V]) GetLessOrEqual(key K) []V {
 	pos := s.GetIndexOfGreater(key)
-	return s.values[:pos]
+	res := s.values[:pos]
+	s.stats.rangeSize(len(res))
+	return res
 }
 
 func (s *NoLockSortedMap[K, V]) GetByInclusiveRange(startKey K, endKey K) []V {
 	startPos := s.GetIndexOfGreaterOrEqual(startKey)
 	endPos := s.GetIndexOfGreater(endKey)
-	return s.values[startPos:endPos]
+	res := s.values[startPos:endPos]
+	s.stats.rangeSize(len(res))
+	return res
 }
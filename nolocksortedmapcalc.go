@@ -2,13 +2,15 @@ package sortedmap
 
 import (
 	"golang.org/x/exp/constraints"
-	"golang.org/x/exp/slices"
 )
 
 type NoLockSortedMapCalc[K constraints.Ordered, V any] struct {
 	keys    []K
 	values  []V
 	calcKey func(V) K
+	hook    func(ChangeEvent[K, V])
+	stats   *statsCounters
+	layout  *layout[K, V]
 }
 
 func NewNoLockSortedMapCalc[K constraints.Ordered, V any](capacity int, calcKey func(V) K) *NoLockSortedMapCalc[K, V] {
@@ -19,6 +21,16 @@ func NewNoLockSortedMapCalc[K constraints.Ordered, V any](capacity int, calcKey
 	}
 }
 
+func NewNoLockSortedMapCalcWithOptions[K constraints.Ordered, V any](calcKey func(V) K, opts ...Option) *NoLockSortedMapCalc[K, V] {
+	l, capacity := newLayout[K, V](opts)
+	return &NoLockSortedMapCalc[K, V]{
+		keys:    l.makeKeys(capacity),
+		values:  l.makeValues(capacity),
+		calcKey: calcKey,
+		layout:  l,
+	}
+}
+
 func (s *NoLockSortedMapCalc[K, V]) Size() int {
 	return len(s.values)
 }
@@ -28,27 +40,54 @@ func (s *NoLockSortedMapCalc[K, V]) Capacity() int {
 }
 
 func (s *NoLockSortedMapCalc[K, V]) ExtendCapacityTo(newCap int) {
+	newCap = s.layout.limit(newCap)
 	if s.Capacity() < newCap {
-		s.keys = append(make([]K, 0, newCap), s.keys...)
-		s.values = append(make([]V, 0, newCap), s.values...)
+		s.keys = append(s.layout.makeKeys(newCap), s.keys...)
+		s.values = append(s.layout.makeValues(newCap), s.values...)
+		s.stats.resized()
 	}
 }
 
 func (s *NoLockSortedMapCalc[K, V]) Clear() {
+	if s.hook != nil {
+		for i := range s.keys {
+			s.hook(ChangeEvent[K, V]{Kind: ChangeDelete, Key: s.keys[i], Value: s.values[i]})
+		}
+	}
+	s.stats.deleted(len(s.values))
 	s.keys = s.keys[:0]
 	s.values = s.values[:0]
 }
 
+func (s *NoLockSortedMapCalc[K, V]) clone(extraCapacity int) *NoLockSortedMapCalc[K, V] {
+	return &NoLockSortedMapCalc[K, V]{
+		keys:    append(s.layout.makeKeys(len(s.keys)+extraCapacity), s.keys...),
+		values:  append(s.layout.makeValues(len(s.values)+extraCapacity), s.values...),
+		calcKey: s.calcKey,
+		stats:   s.stats,
+		layout:  s.layout,
+	}
+}
+
 func (s *NoLockSortedMapCalc[K, V]) Insert(value V) int {
+	pos, _ := s.InsertChecked(value)
+	return pos
+}
+
+// InsertChecked is like Insert, but returns ErrMaxCapacity when the key is
+// new and the map already holds WithMaxCapacity entries.
+func (s *NoLockSortedMapCalc[K, V]) InsertChecked(value V) (int, error) {
 	key := s.calcKey(value)
-	pos, exists := slices.BinarySearch(s.keys, key)
+	pos, exists := s.layout.search(s.keys, key)
 	if exists {
-		return -1
+		s.stats.duplicate()
+		return -1, nil
 	}
 
-	s.keys = insertAt(s.keys, pos, key)
-	s.values = insertAt(s.values, pos, value)
-	return pos
+	if !s.insertAtPos(pos, key, value) {
+		return -1, ErrMaxCapacity
+	}
+	return pos, nil
 }
 
 // TODO
@@ -59,26 +98,27 @@ func (s *NoLockSortedMapCalc[K, V]) Insert(value V) int {
 func (s *NoLockSortedMapCalc[K, V]) InsertWithAfterHint(value V, afterIndex int) int {
 	key := s.calcKey(value)
 	partialKeys := s.keys[afterIndex:]
-	pos, exists := slices.BinarySearch(partialKeys, key)
+	pos, exists := s.layout.search(partialKeys, key)
 	if exists {
+		s.stats.duplicate()
 		return -1
 	}
 
 	actualPos := afterIndex + pos
-	s.keys =  insertAt(s.keys, actualPos, key)
-	s.values =  insertAt(s.values, actualPos, value)
+	if !s.insertAtPos(actualPos, key, value) {
+		return -1
+	}
 	return actualPos
 }
 
 func (s *NoLockSortedMapCalc[K, V]) Delete(value V) int {
 	key := s.calcKey(value)
-	pos, exists := slices.BinarySearch(s.keys, key)
+	pos, exists := s.layout.search(s.keys, key)
 	if !exists {
 		return -1
 	}
 
-	s.keys = deleteAt(s.keys, pos)
-	s.values = deleteAt(s.values, pos)
+	s.deleteAtPos(pos)
 	return pos
 }
 
@@ -90,17 +130,107 @@ func (s *NoLockSortedMapCalc[K, V]) Delete(value V) int {
 func (s *NoLockSortedMapCalc[K, V]) DeleteWithAfterHint(value V, afterIndex int) int {
 	key := s.calcKey(value)
 	partialKeys := s.keys[afterIndex:]
-	pos, exists := slices.BinarySearch(partialKeys, key)
+	pos, exists := s.layout.search(partialKeys, key)
 	if !exists {
 		return -1
 	}
 
 	actualPos := afterIndex + pos
-	s.keys =  deleteAt(s.keys, actualPos)
-	s.values =  deleteAt(s.values, actualPos)
+	s.deleteAtPos(actualPos)
 	return actualPos
 }
 
+func (s *NoLockSortedMapCalc[K, V]) replaceAll(keys []K, values []V) {
+	if s.hook != nil {
+		s.Clear()
+		for i := range keys {
+			s.hook(ChangeEvent[K, V]{Kind: ChangeInsert, Index: i, Key: keys[i], Value: values[i]})
+		}
+	}
+	s.keys = keys
+	s.values = values
+}
+
+// reserve makes room for n more entries following the growth options. It
+// returns false when they would exceed the maximum capacity.
+func (s *NoLockSortedMapCalc[K, V]) reserve(n int) bool {
+	need := len(s.values) + n
+	if need <= cap(s.keys) && need <= cap(s.values) {
+		return true
+	}
+	newCap, ok := s.layout.grow(cap(s.values), need)
+	if ok && newCap >= 0 {
+		s.keys = append(s.layout.makeKeys(newCap), s.keys...)
+		s.values = append(s.layout.makeValues(newCap), s.values...)
+	}
+	return ok
+}
+
+func (s *NoLockSortedMapCalc[K, V]) insertAtPos(pos int, key K, value V) bool {
+	oldCap := cap(s.values)
+	if !s.reserve(1) {
+		s.stats.capacityRejected()
+		return false
+	}
+	s.keys = insertAt(s.keys, pos, key)
+	s.values = insertAt(s.values, pos, value)
+	s.stats.inserted(oldCap, cap(s.values))
+	if s.hook != nil {
+		s.hook(ChangeEvent[K, V]{Kind: ChangeInsert, Index: pos, Key: key, Value: value})
+	}
+	return true
+}
+
+func (s *NoLockSortedMapCalc[K, V]) deleteAtPos(pos int) {
+	if s.hook != nil {
+		s.hook(ChangeEvent[K, V]{Kind: ChangeDelete, Index: pos, Key: s.keys[pos], Value: s.values[pos]})
+	}
+	s.keys = deleteAt(s.keys, pos)
+	s.values = deleteAt(s.values, pos)
+	s.stats.deleted(1)
+}
+
+func (s *NoLockSortedMapCalc[K, V]) updateAt(pos int, value V) {
+	if s.hook != nil {
+		s.hook(ChangeEvent[K, V]{Kind: ChangeUpdate, Index: pos, Key: s.keys[pos], Value: value, OldValue: s.values[pos]})
+	}
+	s.values[pos] = value
+}
+
+// set inserts the value or overwrites the value having the same key.
+// It returns -1 when a new key does not fit in the maximum capacity.
+func (s *NoLockSortedMapCalc[K, V]) set(value V) int {
+	key := s.calcKey(value)
+	pos, exists := s.layout.search(s.keys, key)
+	if exists {
+		s.updateAt(pos, value)
+		return pos
+	}
+
+	if !s.insertAtPos(pos, key, value) {
+		return -1
+	}
+	return pos
+}
+
+func (s *NoLockSortedMapCalc[K, V]) deleteRange(startKey K, endKey K) {
+	s.deleteBetween(s.GetIndexOfGreaterOrEqual(startKey), s.GetIndexOfGreater(endKey))
+}
+
+// deleteBetween deletes the entries in [startPos, endPos).
+func (s *NoLockSortedMapCalc[K, V]) deleteBetween(startPos int, endPos int) {
+	if startPos < endPos {
+		if s.hook != nil {
+			for i := startPos; i < endPos; i++ {
+				s.hook(ChangeEvent[K, V]{Kind: ChangeDelete, Index: startPos, Key: s.keys[i], Value: s.values[i]})
+			}
+		}
+		s.keys = append(s.keys[:startPos], s.keys[endPos:]...)
+		s.values = append(s.values[:startPos], s.values[endPos:]...)
+		s.stats.deleted(endPos - startPos)
+	}
+}
+
 func (s *NoLockSortedMapCalc[K, V]) InsertAll(values []V) {
 	s.ExtendCapacityTo(s.Size() + len(values))
 
@@ -114,8 +244,24 @@ func (s *NoLockSortedMapCalc[K, V]) InsertAllOrdered(values []V) {
 
 	hint := 0
 	for i := range values {
-		hint = s.InsertWithAfterHint(values[i], hint)
+		if pos := s.InsertWithAfterHint(values[i], hint); pos >= 0 {
+			hint = pos
+		}
+	}
+}
+
+// InsertAllChecked is like InsertAll, but returns ErrMaxCapacity when some
+// of the values did not fit.
+func (s *NoLockSortedMapCalc[K, V]) InsertAllChecked(values []V) error {
+	s.ExtendCapacityTo(s.Size() + len(values))
+
+	var err error
+	for i := range values {
+		if _, e := s.InsertChecked(values[i]); e != nil {
+			err = e
+		}
 	}
+	return err
 }
 
 
@@ -137,41 +283,52 @@ func (s *NoLockSortedMapCalc[K, V]) DeleteAllOrdered(values []V) {
 }
 
 func (s *NoLockSortedMapCalc[K, V]) Contains(key K) bool {
-	_, exists := slices.BinarySearch(s.keys, key)
+	_, exists := s.layout.search(s.keys, key)
+	s.stats.lookup(exists)
 	return exists
 }
 
 func (s *NoLockSortedMapCalc[K, V]) GetIndexOfGreater(key K) int {
-	pos, exists := slices.BinarySearch(s.keys, key)
+	pos, exists := s.layout.search(s.keys, key)
 	if exists {
 		pos++ // does not include multiple same values
 	}
 	return pos
 }
 func (s *NoLockSortedMapCalc[K, V]) GetIndexOfGreaterOrEqual(key K) int {
-	pos, _ := slices.BinarySearch(s.keys, key)
+	pos, _ := s.layout.search(s.keys, key)
 	return pos
 }
 
 func (s *NoLockSortedMapCalc[K, V]) GetGreater(key K) []V {
 	pos := s.GetIndexOfGreater(key)
-	return s.values[pos:]
+	res := s.values[pos:]
+	s.stats.rangeSize(len(res))
+	return res
 }
 func (s *NoLockSortedMapCalc[K, V]) GetGreaterOrEqual(key K) []V {
 	pos := s.GetIndexOfGreaterOrEqual(key)
-	return s.values[pos:]
+	res := s.values[pos:]
+	s.stats.rangeSize(len(res))
+	return res
 }
 func (s *NoLockSortedMapCalc[K, V]) GetLess(key K) []V {
 	pos := s.GetIndexOfGreaterOrEqual(key)
-	return s.values[:pos]
+	res := s.values[:pos]
+	s.stats.rangeSize(len(res))
+	return res
 }
 func (s *NoLockSortedMapCalc[K, V]) GetLessOrEqual(key K) []V {
 	pos := s.GetIndexOfGreater(key)
-	return s.values[:pos]
+	res := s.values[:pos]
+	s.stats.rangeSize(len(res))
+	return res
 }
 
 func (s *NoLockSortedMapCalc[K, V]) GetByInclusiveRange(startKey K, endKey K) []V {
 	startPos := s.GetIndexOfGreaterOrEqual(startKey)
 	endPos := s.GetIndexOfGreater(endKey)
-	return s.values[startPos:endPos]
+	res := s.values[startPos:endPos]
+	s.stats.rangeSize(len(res))
+	return res
 }
@@ -2,11 +2,14 @@ package sortedmap
 
 import (
 	"golang.org/x/exp/constraints"
-	"golang.org/x/exp/slices"
 )
 
 type NoLockSortedSet[K constraints.Ordered] struct {
 	values []K
+	hook   func(ChangeEvent[K, struct{}])
+	stats  *statsCounters
+	layout *layout[K, struct{}]
+	filter *bloomFilter[K]
 }
 
 func NewNoLockSortedSet[K constraints.Ordered](capacity int) *NoLockSortedSet[K] {
@@ -15,6 +18,16 @@ func NewNoLockSortedSet[K constraints.Ordered](capacity int) *NoLockSortedSet[K]
 	}
 }
 
+// NewNoLockSortedSetWithOptions creates a set configured by opts. An
+// allocator for the set is given as WithAllocator(func(int) []K).
+func NewNoLockSortedSetWithOptions[K constraints.Ordered](opts ...Option) *NoLockSortedSet[K] {
+	l, capacity := newLayout[K, struct{}](opts)
+	return &NoLockSortedSet[K]{
+		values: l.makeKeys(capacity),
+		layout: l,
+	}
+}
+
 func (s *NoLockSortedSet[K]) Size() int {
 	return len(s.values)
 }
@@ -24,23 +37,51 @@ func (s *NoLockSortedSet[K]) Capacity() int {
 }
 
 func (s *NoLockSortedSet[K]) ExtendCapacityTo(newCap int) {
+	newCap = s.layout.limit(newCap)
 	if s.Capacity() < newCap {
-		s.values = append(make([]K, 0, newCap), s.values...)
+		s.values = append(s.layout.makeKeys(newCap), s.values...)
+		s.stats.resized()
 	}
 }
 
 func (s *NoLockSortedSet[K]) Clear() {
+	if s.hook != nil {
+		for i := range s.values {
+			s.hook(ChangeEvent[K, struct{}]{Kind: ChangeDelete, Key: s.values[i]})
+		}
+	}
+	s.stats.deleted(len(s.values))
 	s.values = s.values[:0]
+	s.filter.replaced(s.values)
+}
+
+func (s *NoLockSortedSet[K]) clone(extraCapacity int) *NoLockSortedSet[K] {
+	return &NoLockSortedSet[K]{
+		values: append(s.layout.makeKeys(len(s.values)+extraCapacity), s.values...),
+		stats:  s.stats,
+		layout: s.layout,
+		filter: s.filter.clone(),
+	}
 }
 
 func (s *NoLockSortedSet[K]) Insert(value K) int {
-	pos, exists := slices.BinarySearch(s.values, value)
+	pos, _ := s.InsertChecked(value)
+	return pos
+}
+
+// InsertChecked is like Insert, but returns ErrMaxCapacity when the value
+// is new and the set already holds WithMaxCapacity values.
+func (s *NoLockSortedSet[K]) InsertChecked(value K) (int, error) {
+	pos, exists := s.layout.search(s.values, value)
 	if exists {
-		return -1
+		s.stats.duplicate()
+		return -1, nil
 	}
 
-	s.values = insertAt(s.values, pos, value)
-	return pos
+	if !s.insertAtPos(pos, value) {
+		return -1, ErrMaxCapacity
+	}
+	return pos, nil
 }
 
 // TODO
@@ -50,23 +91,26 @@ func (s *NoLockSortedSet[K]) Insert(value K) int {
 
 func (s *NoLockSortedSet[K]) InsertWithAfterHint(value K, afterIndex int) int {
 	partialValues := s.values[afterIndex:]
-	pos, exists := slices.BinarySearch(partialValues, value)
+	pos, exists := s.layout.search(partialValues, value)
 	if exists {
+		s.stats.duplicate()
 		return -1
 	}
 
 	actualPos := afterIndex + pos
-	s.values = insertAt(s.values, actualPos, value)
+	if !s.insertAtPos(actualPos, value) {
+		return -1
+	}
 	return actualPos
 }
 
 func (s *NoLockSortedSet[K]) Delete(value K) int {
-	pos, exists := slices.BinarySearch(s.values, value)
+	pos, exists := s.layout.search(s.values, value)
 	if !exists {
 		return -1
 	}
 
-	s.values = deleteAt(s.values, pos)
+	s.deleteAtPos(pos)
 	return pos
 }
 
@@ -77,16 +121,83 @@ func (s *NoLockSortedSet[K]) Delete(value K) int {
 
 func (s *NoLockSortedSet[K]) DeleteWithAfterHint(value K, afterIndex int) int {
 	partialValues := s.values[afterIndex:]
-	pos, exists := slices.BinarySearch(partialValues, value)
+	pos, exists := s.layout.search(partialValues, value)
 	if !exists {
 		return -1
 	}
 
 	actualPos := afterIndex + pos
-	s.values = deleteAt(s.values, actualPos)
+	s.deleteAtPos(actualPos)
 	return actualPos
 }
 
+func (s *NoLockSortedSet[K]) replaceAll(values []K) {
+	if s.hook != nil {
+		s.Clear()
+		for i := range values {
+			s.hook(ChangeEvent[K, struct{}]{Kind: ChangeInsert, Index: i, Key: values[i]})
+		}
+	}
+	s.values = values
+	s.filter.replaced(values)
+}
+
+// reserve makes room for n more entries following the growth options. It
+// returns false when they would exceed the maximum capacity.
+func (s *NoLockSortedSet[K]) reserve(n int) bool {
+	need := len(s.values) + n
+	if need <= cap(s.values) {
+		return true
+	}
+	newCap, ok := s.layout.grow(cap(s.values), need)
+	if ok && newCap >= 0 {
+		s.values = append(s.layout.makeKeys(newCap), s.values...)
+	}
+	return ok
+}
+
+func (s *NoLockSortedSet[K]) insertAtPos(pos int, value K) bool {
+	oldCap := cap(s.values)
+	if !s.reserve(1) {
+		s.stats.capacityRejected()
+		return false
+	}
+	s.values = insertAt(s.values, pos, value)
+	s.stats.inserted(oldCap, cap(s.values))
+	s.filter.inserted(value, s.values)
+	if s.hook != nil {
+		s.hook(ChangeEvent[K, struct{}]{Kind: ChangeInsert, Index: pos, Key: value})
+	}
+	return true
+}
+
+func (s *NoLockSortedSet[K]) deleteAtPos(pos int) {
+	if s.hook != nil {
+		s.hook(ChangeEvent[K, struct{}]{Kind: ChangeDelete, Index: pos, Key: s.values[pos]})
+	}
+	s.values = deleteAt(s.values, pos)
+	s.stats.deleted(1)
+	s.filter.deleted(1, s.values)
+}
+
+func (s *NoLockSortedSet[K]) deleteRange(startValue K, endValue K) {
+	s.deleteBetween(s.GetIndexOfGreaterOrEqual(startValue), s.GetIndexOfGreater(endValue))
+}
+
+// deleteBetween deletes the entries in [startPos, endPos).
+func (s *NoLockSortedSet[K]) deleteBetween(startPos int, endPos int) {
+	if startPos < endPos {
+		if s.hook != nil {
+			for i := startPos; i < endPos; i++ {
+				s.hook(ChangeEvent[K, struct{}]{Kind: ChangeDelete, Index: startPos, Key: s.values[i]})
+			}
+		}
+		s.values = append(s.values[:startPos], s.values[endPos:]...)
+		s.stats.deleted(endPos - startPos)
+		s.filter.deleted(endPos-startPos, s.values)
+	}
+}
+
 func (s *NoLockSortedSet[K]) InsertAll(values []K) {
 	s.ExtendCapacityTo(s.Size() + len(values))
 
@@ -100,10 +211,26 @@ func (s *NoLockSortedSet[K]) InsertAllOrdered(values []K) {
 
 	hint := 0
 	for i := range values {
-		hint = s.InsertWithAfterHint(values[i], hint)
+		if pos := s.InsertWithAfterHint(values[i], hint); pos >= 0 {
+			hint = pos
+		}
 	}
 }
 
+// InsertAllChecked is like InsertAll, but returns ErrMaxCapacity when some
+// of the values did not fit.
+func (s *NoLockSortedSet[K]) InsertAllChecked(values []K) error {
+	s.ExtendCapacityTo(s.Size() + len(values))
+
+	var err error
+	for i := range values {
+		if _, e := s.InsertChecked(values[i]); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
 func (s *NoLockSortedSet[K]) DeleteAll(values []K) {
 	s.ExtendCapacityTo(s.Size() + len(values))
 
@@ -122,41 +249,56 @@ func (s *NoLockSortedSet[K]) DeleteAllOrdered(values []K) {
 }
 
 func (s *NoLockSortedSet[K]) Contains(value K) bool {
-	_, exists := slices.BinarySearch(s.values, value)
+	if !s.filter.mayContain(value) {
+		s.stats.filtered()
+		return false
+	}
+	_, exists := s.layout.search(s.values, value)
+	s.stats.lookup(exists)
 	return exists
 }
 
 func (s *NoLockSortedSet[K]) GetIndexOfGreater(value K) int {
-	pos, exists := slices.BinarySearch(s.values, value)
+	pos, exists := s.layout.search(s.values, value)
 	if exists {
 		pos++ // does not include multiple same values
 	}
 	return pos
 }
 func (s *NoLockSortedSet[K]) GetIndexOfGreaterOrEqual(value K) int {
-	pos, _ := slices.BinarySearch(s.values, value)
+	pos, _ := s.layout.search(s.values, value)
 	return pos
 }
 
 func (s *NoLockSortedSet[K]) GetGreater(value K) []K {
 	pos := s.GetIndexOfGreater(value)
-	return s.values[pos:]
+	res := s.values[pos:]
+	s.stats.rangeSize(len(res))
+	return res
 }
 func (s *NoLockSortedSet[K]) GetGreaterOrEqual(value K) []K {
 	pos := s.GetIndexOfGreaterOrEqual(value)
-	return s.values[pos:]
+	res := s.values[pos:]
+	s.stats.rangeSize(len(res))
+	return res
 }
 func (s *NoLockSortedSet[K]) GetLess(value K) []K {
 	pos := s.GetIndexOfGreaterOrEqual(value)
-	return s.values[:pos]
+	res := s.values[:pos]
+	s.stats.rangeSize(len(res))
+	return res
 }
 func (s *NoLockSortedSet[K]) GetLessOrEqual(value K) []K {
 	pos := s.GetIndexOfGreater(value)
-	return s.values[:pos]
+	res := s.values[:pos]
+	s.stats.rangeSize(len(res))
+	return res
 }
 
 func (s *NoLockSortedSet[K]) GetByInclusiveRange(startValue K, endValue K) []K {
 	startPos := s.GetIndexOfGreaterOrEqual(startValue)
 	endPos := s.GetIndexOfGreater(endValue)
-	return s.values[startPos:endPos]
+	res := s.values[startPos:endPos]
+	s.stats.rangeSize(len(res))
+	return res
 }
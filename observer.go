@@ -0,0 +1,266 @@
+package sortedmap
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/exp/constraints"
+)
+
+type ChangeKind int
+
+const (
+	ChangeInsert ChangeKind = iota
+	ChangeDelete
+	ChangeUpdate
+)
+
+var changeKindNames = [...]string{ChangeInsert: "insert", ChangeDelete: "delete", ChangeUpdate: "update"}
+
+func (k ChangeKind) String() string {
+	if k < 0 || int(k) >= len(changeKindNames) {
+		return fmt.Sprintf("ChangeKind(%d)", int(k))
+	}
+	return changeKindNames[k]
+}
+
+func (k ChangeKind) MarshalText() ([]byte, error) {
+	if k < 0 || int(k) >= len(changeKindNames) {
+		return nil, fmt.Errorf("sortedmap: invalid change kind %d", int(k))
+	}
+	return []byte(changeKindNames[k]), nil
+}
+
+func (k *ChangeKind) UnmarshalText(text []byte) error {
+	for i, name := range changeKindNames {
+		if string(text) == name {
+			*k = ChangeKind(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("sortedmap: invalid change kind %q", text)
+}
+
+// ChangeEvent describes a single change. Index is the position of the entry
+// at the time of the change, so replaying events in order reproduces the
+// container. OldValue is only set for ChangeUpdate.
+type ChangeEvent[K constraints.Ordered, V any] struct {
+	Kind     ChangeKind
+	Index    int
+	Key      K
+	Value    V
+	OldValue V
+}
+
+type Observer[K constraints.Ordered, V any] interface {
+	OnInsert(index int, key K, value V)
+	OnDelete(index int, key K, value V)
+	OnUpdate(index int, key K, oldValue V, newValue V)
+}
+
+type ObserverFuncs[K constraints.Ordered, V any] struct {
+	Insert func(index int, key K, value V)
+	Delete func(index int, key K, value V)
+	Update func(index int, key K, oldValue V, newValue V)
+}
+
+func (o ObserverFuncs[K, V]) OnInsert(index int, key K, value V) {
+	if o.Insert != nil {
+		o.Insert(index, key, value)
+	}
+}
+
+func (o ObserverFuncs[K, V]) OnDelete(index int, key K, value V) {
+	if o.Delete != nil {
+		o.Delete(index, key, value)
+	}
+}
+
+func (o ObserverFuncs[K, V]) OnUpdate(index int, key K, oldValue V, newValue V) {
+	if o.Update != nil {
+		o.Update(index, key, oldValue, newValue)
+	}
+}
+
+type SetObserver[K constraints.Ordered] interface {
+	OnInsert(index int, value K)
+	OnDelete(index int, value K)
+}
+
+type SetObserverFuncs[K constraints.Ordered] struct {
+	Insert func(index int, value K)
+	Delete func(index int, value K)
+}
+
+func (o SetObserverFuncs[K]) OnInsert(index int, value K) {
+	if o.Insert != nil {
+		o.Insert(index, value)
+	}
+}
+
+func (o SetObserverFuncs[K]) OnDelete(index int, value K) {
+	if o.Delete != nil {
+		o.Delete(index, value)
+	}
+}
+
+func notifyObserver[K constraints.Ordered, V any](o Observer[K, V], e ChangeEvent[K, V]) {
+	switch e.Kind {
+	case ChangeInsert:
+		o.OnInsert(e.Index, e.Key, e.Value)
+	case ChangeDelete:
+		o.OnDelete(e.Index, e.Key, e.Value)
+	case ChangeUpdate:
+		o.OnUpdate(e.Index, e.Key, e.OldValue, e.Value)
+	}
+}
+
+func notifySetObserver[K constraints.Ordered](o SetObserver[K], e ChangeEvent[K, struct{}]) {
+	switch e.Kind {
+	case ChangeInsert:
+		o.OnInsert(e.Index, e.Key)
+	case ChangeDelete:
+		o.OnDelete(e.Index, e.Key)
+	}
+}
+
+type rwLocker interface {
+	sync.Locker
+	RLock()
+	RUnlock()
+}
+
+type subscription[K constraints.Ordered, V any] struct {
+	fn func(ChangeEvent[K, V])
+}
+
+// observedLock is the lock of the locked wrappers. Changes recorded while it
+// is held are delivered to the subscribers by Unlock, after the write lock is
+// released and in the order the changes happened. Subscribers may read the
+// container but must not modify it.
+type observedLock[K constraints.Ordered, V any] struct {
+	sync.RWMutex
+
+	// guarded by RWMutex
+	subscriptions []*subscription[K, V]
+	pending       []ChangeEvent[K, V]
+	tickets       uint64
+
+	// served is the ticket whose events are delivered next, guarded by
+	// dispatch. turn is signaled when it changes.
+	dispatch sync.Mutex
+	served   uint64
+	turn     sync.Cond
+
+	stats atomic.Value // *statsCounters
+}
+
+func (l *observedLock[K, V]) record(e ChangeEvent[K, V]) {
+	l.pending = append(l.pending, e)
+}
+
+// Unlock takes a ticket while still holding the write lock, so that the
+// events are delivered in the order of the writes, but waits for its turn
+// only after releasing it, so that subscribers and readers never wait on
+// each other.
+func (l *observedLock[K, V]) Unlock() {
+	if len(l.pending) == 0 {
+		l.RWMutex.Unlock()
+		return
+	}
+
+	events := l.pending
+	subscriptions := l.subscriptions
+	l.pending = nil
+	ticket := l.tickets
+	l.tickets++
+	if l.turn.L == nil {
+		l.turn.L = &l.dispatch
+	}
+	l.RWMutex.Unlock()
+
+	l.dispatch.Lock()
+	for l.served != ticket {
+		l.turn.Wait()
+	}
+	l.dispatch.Unlock()
+	defer func() {
+		l.dispatch.Lock()
+		l.served++
+		l.turn.Broadcast()
+		l.dispatch.Unlock()
+	}()
+
+	for _, e := range events {
+		for _, sub := range subscriptions {
+			sub.fn(e)
+		}
+	}
+}
+
+// subscribe registers fn and returns the function removing it. setHook is
+// called with the recording hook while there is at least one subscriber and
+// with nil otherwise.
+func (l *observedLock[K, V]) subscribe(fn func(ChangeEvent[K, V]), setHook func(func(ChangeEvent[K, V]))) func() {
+	sub := &subscription[K, V]{fn: fn}
+
+	l.RWMutex.Lock()
+	l.subscriptions = append(l.subscriptions[:len(l.subscriptions):len(l.subscriptions)], sub)
+	setHook(l.record)
+	l.RWMutex.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.RWMutex.Lock()
+			subscriptions := make([]*subscription[K, V], 0, len(l.subscriptions))
+			for _, s := range l.subscriptions {
+				if s != sub {
+					subscriptions = append(subscriptions, s)
+				}
+			}
+			l.subscriptions = subscriptions
+			if len(subscriptions) == 0 {
+				setHook(nil)
+			}
+			l.RWMutex.Unlock()
+		})
+	}
+}
+
+func (s *SortedMap[K, V]) setHook(hook func(ChangeEvent[K, V])) {
+	s.s.hook = hook
+}
+
+// Subscribe registers o to be notified synchronously after each change.
+// Call the returned function to unsubscribe.
+func (s *SortedMap[K, V]) Subscribe(o Observer[K, V]) func() {
+	return s.m.subscribe(func(e ChangeEvent[K, V]) {
+		notifyObserver(o, e)
+	}, s.setHook)
+}
+
+func (s *SortedMapCalc[K, V]) setHook(hook func(ChangeEvent[K, V])) {
+	s.s.hook = hook
+}
+
+// Subscribe registers o to be notified synchronously after each change.
+// Call the returned function to unsubscribe.
+func (s *SortedMapCalc[K, V]) Subscribe(o Observer[K, V]) func() {
+	return s.m.subscribe(func(e ChangeEvent[K, V]) {
+		notifyObserver(o, e)
+	}, s.setHook)
+}
+
+func (s *SortedSet[K]) setHook(hook func(ChangeEvent[K, struct{}])) {
+	s.s.hook = hook
+}
+
+// Subscribe registers o to be notified synchronously after each change.
+// Call the returned function to unsubscribe.
+func (s *SortedSet[K]) Subscribe(o SetObserver[K]) func() {
+	return s.m.subscribe(func(e ChangeEvent[K, struct{}]) {
+		notifySetObserver(o, e)
+	}, s.setHook)
+}
@@ -0,0 +1,178 @@
+package sortedmap_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sapphi-red/sortedmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func recordingObserver(events *[]string) sortedmap.ObserverFuncs[int, string] {
+	return sortedmap.ObserverFuncs[int, string]{
+		Insert: func(index int, key int, value string) {
+			*events = append(*events, fmt.Sprintf("insert %d %d %s", index, key, value))
+		},
+		Delete: func(index int, key int, value string) {
+			*events = append(*events, fmt.Sprintf("delete %d %d %s", index, key, value))
+		},
+		Update: func(index int, key int, oldValue string, newValue string) {
+			*events = append(*events, fmt.Sprintf("update %d %d %s->%s", index, key, oldValue, newValue))
+		},
+	}
+}
+
+func TestSortedMap_Subscribe(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMap[int, string](5)
+	var events []string
+	unsubscribe := m.Subscribe(recordingObserver(&events))
+
+	m.Insert(2, "2")
+	m.Insert(2, "dup")
+	m.InsertAll([]int{3, 1}, []string{"3", "1"})
+	m.CompareAndSwap(3, "3", "three", eqString)
+	m.Delete(1)
+	m.Clear()
+	assert.Equal(t, []string{
+		"insert 0 2 2",
+		"insert 1 3 3",
+		"insert 0 1 1",
+		"update 2 3 3->three",
+		"delete 0 1 1",
+		"delete 0 2 2",
+		"delete 0 3 three",
+	}, events)
+
+	unsubscribe()
+	unsubscribe()
+	m.Insert(5, "5")
+	assert.Equal(t, 7, len(events))
+}
+
+func TestSortedMap_SubscribeBatch(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMap[int, string](5)
+	m.InsertAll([]int{1, 2, 3}, []string{"1", "2", "3"})
+
+	var events []string
+	m.Subscribe(recordingObserver(&events))
+
+	m.Apply(sortedmap.NewMapBatch[int, string]().DeleteRange(1, 2).Set(3, "three"))
+	assert.Equal(t, []string{
+		"delete 0 1 1",
+		"delete 0 2 2",
+		"update 0 3 3->three",
+	}, events)
+
+	events = nil
+	m.Update(func(tx *sortedmap.NoLockSortedMap[int, string]) error {
+		tx.Insert(4, "4")
+		return fmt.Errorf("abort")
+	})
+	assert.Empty(t, events)
+
+	m.Update(func(tx *sortedmap.NoLockSortedMap[int, string]) error {
+		tx.Insert(4, "4")
+		return nil
+	})
+	assert.Equal(t, []string{"insert 1 4 4"}, events)
+}
+
+func TestSortedMap_SubscribeReadsMap(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMap[int, string](5)
+	var sizes []int
+	m.Subscribe(sortedmap.ObserverFuncs[int, string]{
+		Insert: func(index int, key int, value string) {
+			sizes = append(sizes, m.Size())
+		},
+	})
+	m.Insert(1, "1")
+	m.Insert(2, "2")
+	assert.Equal(t, []int{1, 2}, sizes)
+}
+
+func TestSortedMap_SubscribeConcurrentInsert(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMap[int, string](5)
+	var keys []int
+	inserted := make(chan struct{})
+	m.Subscribe(sortedmap.ObserverFuncs[int, string]{
+		Insert: func(index int, key int, value string) {
+			keys = append(keys, key)
+			if key != 1 {
+				return
+			}
+			// The insert of 2 waits for this delivery, but must not keep
+			// the reads below waiting on it.
+			go func() {
+				m.Insert(2, "2")
+				close(inserted)
+			}()
+			for !m.Contains(2) {
+				time.Sleep(time.Millisecond)
+			}
+		},
+	})
+
+	done := make(chan struct{})
+	go func() {
+		m.Insert(1, "1")
+		<-inserted
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("deadlock between a subscriber and a concurrent insert")
+	}
+	assert.Equal(t, []int{1, 2}, keys)
+}
+
+func TestSortedMapCalc_Subscribe(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMapCalc(5, safeAtoi)
+	var events []string
+	m.Subscribe(sortedmap.ObserverFuncs[int, string]{
+		Insert: func(index int, key int, value string) {
+			events = append(events, fmt.Sprintf("insert %d %d %s", index, key, value))
+		},
+		Delete: func(index int, key int, value string) {
+			events = append(events, fmt.Sprintf("delete %d %d %s", index, key, value))
+		},
+	})
+
+	m.InsertAllOrdered([]string{"1", "2"})
+	m.DeleteAll([]string{"1"})
+	assert.Equal(t, []string{"insert 0 1 1", "insert 1 2 2", "delete 0 1 1"}, events)
+}
+
+func TestSortedSet_Subscribe(t *testing.T) {
+	t.Parallel()
+
+	set := sortedmap.NewSortedSet[int](5)
+	var events []string
+	unsubscribe := set.Subscribe(sortedmap.SetObserverFuncs[int]{
+		Insert: func(index int, value int) {
+			events = append(events, fmt.Sprintf("insert %d %d", index, value))
+		},
+		Delete: func(index int, value int) {
+			events = append(events, fmt.Sprintf("delete %d %d", index, value))
+		},
+	})
+
+	set.Insert(5)
+	set.InsertWithAfterHint(7, 1)
+	set.DeleteWithAfterHint(5, 0)
+	set.Clear()
+	unsubscribe()
+	set.Insert(1)
+	assert.Equal(t, []string{"insert 0 5", "insert 1 7", "delete 0 5", "delete 0 7"}, events)
+}
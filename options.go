@@ -0,0 +1,226 @@
+package sortedmap
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/exp/constraints"
+	"golang.org/x/exp/slices"
+)
+
+var ErrMaxCapacity = errors.New("sortedmap: maximum capacity exceeded")
+
+const defaultGrowthFactor = 2
+
+type options struct {
+	capacity     int
+	growthFactor float64
+	maxCapacity  int
+	comparator   any
+	descending   bool
+	allocators   []any
+	backend      Backend
+	search       SearchStrategy
+}
+
+type Option func(*options)
+
+func WithCapacity(capacity int) Option {
+	return func(o *options) {
+		o.capacity = capacity
+	}
+}
+
+// WithGrowthFactor sets how much the capacity is multiplied by when an insert
+// does not fit. Factors not above 1 are ignored.
+func WithGrowthFactor(factor float64) Option {
+	return func(o *options) {
+		o.growthFactor = factor
+	}
+}
+
+// WithMaxCapacity limits the number of entries. Growth never reserves more
+// than maxCapacity, and inserting a new key into a full container fails.
+func WithMaxCapacity(maxCapacity int) Option {
+	return func(o *options) {
+		o.maxCapacity = maxCapacity
+	}
+}
+
+// WithComparator orders the keys by cmp instead of <. cmp must return a
+// negative number, zero or a positive number when a is less than, equal to
+// or greater than b. The constructors panic when K is not the key type.
+func WithComparator[K any](cmp func(a K, b K) int) Option {
+	return func(o *options) {
+		o.comparator = cmp
+	}
+}
+
+// WithDescending reverses the order, including the one of WithComparator.
+func WithDescending() Option {
+	return func(o *options) {
+		o.descending = true
+	}
+}
+
+// WithAllocator makes the container allocate its backing slices of element
+// type T with alloc, which must return a slice with at least the requested
+// capacity. It can be given once per element type.
+func WithAllocator[T any](alloc func(capacity int) []T) Option {
+	return func(o *options) {
+		o.allocators = append(o.allocators, alloc)
+	}
+}
+
+// layout holds the resolved options of a container. A nil *layout stands for
+// the defaults: the natural order and the growth of append.
+type layout[K constraints.Ordered, V any] struct {
+	cmp          func(K, K) int
+	growthFactor float64
+	maxCapacity  int
+	allocKeys    func(int) []K
+	allocValues  func(int) []V
+	custom       bool // ordered by WithComparator
+	galloping    bool
+	toFloat      func(K) float64 // set for interpolation search
+}
+
+func resolveOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+func newLayout[K constraints.Ordered, V any](opts []Option) (*layout[K, V], int) {
+	o := resolveOptions(opts)
+
+	l := &layout[K, V]{
+		growthFactor: o.growthFactor,
+		maxCapacity:  o.maxCapacity,
+	}
+	if o.comparator != nil {
+		cmp, ok := o.comparator.(func(K, K) int)
+		if !ok {
+			panic(fmt.Sprintf("sortedmap: comparator %T does not compare the key type", o.comparator))
+		}
+		l.cmp = cmp
+		l.custom = true
+	}
+	if o.descending {
+		if l.cmp == nil {
+			l.cmp = func(a K, b K) int {
+				return compareOrdered(b, a)
+			}
+		} else {
+			cmp := l.cmp
+			l.cmp = func(a K, b K) int {
+				return cmp(b, a)
+			}
+		}
+	}
+	for _, alloc := range o.allocators {
+		if f, ok := alloc.(func(int) []K); ok {
+			l.allocKeys = f
+		}
+		if f, ok := alloc.(func(int) []V); ok {
+			l.allocValues = f
+		}
+	}
+
+	switch o.search {
+	case SearchInterpolation:
+		if l.cmp == nil {
+			l.toFloat = floatOf[K]()
+		}
+	case SearchGalloping:
+		l.galloping = true
+	}
+
+	capacity := o.capacity
+	if l.maxCapacity > 0 {
+		capacity = minInt(capacity, l.maxCapacity)
+	}
+	return l, capacity
+}
+
+func compareOrdered[K constraints.Ordered](a K, b K) int {
+	switch {
+	case a < b:
+		return -1
+	case b < a:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (l *layout[K, V]) compare(a K, b K) int {
+	if l == nil || l.cmp == nil {
+		return compareOrdered(a, b)
+	}
+	return l.cmp(a, b)
+}
+
+func (l *layout[K, V]) search(keys []K, key K) (int, bool) {
+	if l == nil {
+		return slices.BinarySearch(keys, key)
+	}
+	if l.toFloat != nil {
+		return interpolationSearch(keys, key, l.toFloat)
+	}
+	if l.galloping {
+		return gallopingSearch(keys, key, l.cmp)
+	}
+	if l.cmp == nil {
+		return slices.BinarySearch(keys, key)
+	}
+	return slices.BinarySearchFunc(keys, key, l.cmp)
+}
+
+func (l *layout[K, V]) makeKeys(capacity int) []K {
+	if l == nil || l.allocKeys == nil {
+		return make([]K, 0, capacity)
+	}
+	return l.allocKeys(capacity)[:0]
+}
+
+func (l *layout[K, V]) makeValues(capacity int) []V {
+	if l == nil || l.allocValues == nil {
+		return make([]V, 0, capacity)
+	}
+	return l.allocValues(capacity)[:0]
+}
+
+// limit clamps a requested capacity to the maximum capacity.
+func (l *layout[K, V]) limit(capacity int) int {
+	if l == nil || l.maxCapacity <= 0 {
+		return capacity
+	}
+	return minInt(capacity, l.maxCapacity)
+}
+
+// grow returns the capacity to reallocate to so that need entries fit, or -1
+// when append should decide. ok is false when need exceeds the maximum.
+func (l *layout[K, V]) grow(capacity int, need int) (newCap int, ok bool) {
+	if l == nil {
+		return -1, true
+	}
+	if l.maxCapacity > 0 && need > l.maxCapacity {
+		return 0, false
+	}
+	if l.growthFactor <= 1 && l.maxCapacity <= 0 && l.allocKeys == nil && l.allocValues == nil {
+		return -1, true
+	}
+
+	factor := l.growthFactor
+	if factor <= 1 {
+		factor = defaultGrowthFactor
+	}
+	newCap = int(float64(capacity) * factor)
+	if newCap < need {
+		newCap = need
+	}
+	return l.limit(newCap), true
+}
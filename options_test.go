@@ -0,0 +1,191 @@
+package sortedmap_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/sapphi-red/sortedmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoLockSortedMap_WithDescending(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewNoLockSortedMapWithOptions[int, string](sortedmap.WithDescending())
+	m.InsertAll([]int{1, 3, 2}, []string{"1", "3", "2"})
+	assert.Equal(t, []string{"3", "2", "1"}, m.GetGreaterOrEqual(5))
+	assert.Equal(t, []string{"2", "1"}, m.GetGreater(3))
+	assert.Equal(t, []string{"3", "2"}, m.GetByInclusiveRange(3, 2))
+	assert.Equal(t, -1, m.Insert(2, "2"))
+	assert.True(t, m.Contains(1))
+	assert.Equal(t, 2, m.Delete(1))
+
+	// snapshots keep the order of the map they are read into
+	var buf bytes.Buffer
+	_, err := m.Snapshot(sortedmap.OrderedCodec[int](), sortedmap.OrderedCodec[string]()).WriteTo(&buf)
+	assert.NoError(t, err)
+	restored := sortedmap.NewNoLockSortedMapWithOptions[int, string](sortedmap.WithDescending())
+	_, err = restored.Snapshot(sortedmap.OrderedCodec[int](), sortedmap.OrderedCodec[string]()).ReadFrom(&buf)
+	assert.NoError(t, err)
+	assert.True(t, sortedmap.EqualMap(m, restored, eqString))
+}
+
+func TestNoLockSortedSet_WithComparator(t *testing.T) {
+	t.Parallel()
+
+	set := sortedmap.NewNoLockSortedSetWithOptions[string](
+		sortedmap.WithComparator(func(a string, b string) int {
+			return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+		}),
+	)
+	set.InsertAll([]string{"b", "A", "c"})
+	assert.Equal(t, -1, set.Insert("B"))
+	assert.True(t, set.Contains("C"))
+	assert.Equal(t, []string{"A", "b", "c"}, set.GetGreaterOrEqual(""))
+
+	desc := sortedmap.NewNoLockSortedSetWithOptions[string](
+		sortedmap.WithComparator(strings.Compare),
+		sortedmap.WithDescending(),
+	)
+	desc.InsertAll([]string{"b", "a", "c"})
+	assert.Equal(t, []string{"c", "b", "a"}, desc.GetGreaterOrEqual("z"))
+
+	assert.Panics(t, func() {
+		sortedmap.NewNoLockSortedSetWithOptions[int](sortedmap.WithComparator(strings.Compare))
+	})
+}
+
+func TestNoLockSortedMap_WithGrowth(t *testing.T) {
+	t.Parallel()
+
+	allocated := 0
+	m := sortedmap.NewNoLockSortedMapWithOptions[int, string](
+		sortedmap.WithCapacity(2),
+		sortedmap.WithGrowthFactor(1.5),
+		sortedmap.WithMaxCapacity(5),
+		sortedmap.WithAllocator(func(capacity int) []string {
+			allocated += capacity
+			return make([]string, 0, capacity)
+		}),
+	)
+	assert.Equal(t, 2, m.Capacity())
+	assert.Equal(t, 2, allocated)
+
+	m.Insert(1, "1")
+	m.Insert(2, "2")
+	m.Insert(3, "3")
+	assert.Equal(t, 3, m.Capacity())
+	m.Insert(4, "4")
+	assert.Equal(t, 4, m.Capacity())
+	m.Insert(5, "5")
+	assert.Equal(t, 5, m.Capacity())
+	assert.Equal(t, 2+3+4+5, allocated)
+
+	assert.Equal(t, -1, m.Insert(6, "6"))
+	assert.False(t, m.Contains(6))
+	m.ExtendCapacityTo(100)
+	assert.Equal(t, 5, m.Capacity())
+
+	one := sortedmap.NewNoLockSortedMapWithOptions[int, string]()
+	one.Insert(0, "0")
+	p := sortedmap.NewPatch(sortedmap.NewNoLockSortedMapWithOptions[int, string](), one, eqString)
+	assert.ErrorIs(t, m.ApplyPatch(p, nil), sortedmap.ErrMaxCapacity)
+}
+
+func TestNoLockSortedSet_InsertChecked(t *testing.T) {
+	t.Parallel()
+
+	set := sortedmap.NewNoLockSortedSetWithOptions[int](sortedmap.WithMaxCapacity(3))
+	set.EnableStats()
+
+	pos, err := set.InsertChecked(2)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, pos)
+	pos, err = set.InsertChecked(2)
+	assert.NoError(t, err)
+	assert.Equal(t, -1, pos)
+
+	assert.ErrorIs(t, set.InsertAllChecked([]int{1, 3, 4, 5}), sortedmap.ErrMaxCapacity)
+	assert.Equal(t, []int{1, 2, 3}, set.GetGreaterOrEqual(0))
+	pos, err = set.InsertChecked(0)
+	assert.ErrorIs(t, err, sortedmap.ErrMaxCapacity)
+	assert.Equal(t, -1, pos)
+	assert.NoError(t, set.InsertAllChecked([]int{1, 3}))
+
+	set.InsertAllOrdered([]int{0, 1, 4})
+	assert.Equal(t, []int{1, 2, 3}, set.GetGreaterOrEqual(0))
+
+	stats := set.Stats()
+	assert.Equal(t, uint64(5), stats.CapacityRejects)
+	assert.Equal(t, uint64(4), stats.DuplicatesRejected)
+}
+
+func TestSortedMap_InsertChecked(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMapWithOptions[int, string](sortedmap.WithMaxCapacity(1))
+	_, err := m.InsertChecked(1, "1")
+	assert.NoError(t, err)
+	_, err = m.InsertChecked(2, "2")
+	assert.ErrorIs(t, err, sortedmap.ErrMaxCapacity)
+	assert.ErrorIs(t, m.InsertAllChecked([]int{3}, []string{"3"}), sortedmap.ErrMaxCapacity)
+
+	calc := sortedmap.NewSortedMapCalcWithOptions(safeAtoi, sortedmap.WithMaxCapacity(1))
+	assert.ErrorIs(t, calc.InsertAllChecked([]string{"1", "2"}), sortedmap.ErrMaxCapacity)
+	_, err = calc.InsertChecked("3")
+	assert.ErrorIs(t, err, sortedmap.ErrMaxCapacity)
+	assert.Equal(t, []string{"1"}, calc.GetGreaterOrEqual(0))
+}
+
+func TestSortedMap_ComputeWithMaxCapacity(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMapWithOptions[int, string](sortedmap.WithMaxCapacity(1))
+	m.Insert(1, "1")
+
+	actual, loaded, err := m.LoadOrStore(2, "2")
+	assert.ErrorIs(t, err, sortedmap.ErrMaxCapacity)
+	assert.Equal(t, "", actual)
+	assert.False(t, loaded)
+
+	value, ok, err := m.Compute(2, func(string, bool) (string, sortedmap.ComputeOp) {
+		return "2", sortedmap.ComputeStore
+	})
+	assert.ErrorIs(t, err, sortedmap.ErrMaxCapacity)
+	assert.Equal(t, "", value)
+	assert.False(t, ok)
+	assert.Equal(t, []string{"1"}, m.GetGreaterOrEqual(0))
+}
+
+func TestSortedMapCalc_WithMaxCapacity(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMapCalcWithOptions(safeAtoi, sortedmap.WithMaxCapacity(1))
+	actual, loaded, err := m.LoadOrStore("1")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", actual)
+	assert.False(t, loaded)
+
+	actual, loaded, err = m.LoadOrStore("2")
+	assert.ErrorIs(t, err, sortedmap.ErrMaxCapacity)
+	assert.Equal(t, "", actual)
+	assert.False(t, loaded)
+
+	value, ok, err := m.Compute(2, func(string, bool) (string, sortedmap.ComputeOp) {
+		return "2", sortedmap.ComputeStore
+	})
+	assert.ErrorIs(t, err, sortedmap.ErrMaxCapacity)
+	assert.Equal(t, "", value)
+	assert.False(t, ok)
+	assert.Equal(t, []string{"1"}, m.GetGreaterOrEqual(0))
+}
+
+func TestSortedSet_WithDescending(t *testing.T) {
+	t.Parallel()
+
+	set := sortedmap.NewSortedSetWithOptions[int](sortedmap.WithDescending(), sortedmap.WithCapacity(4))
+	set.InsertAll([]int{1, 5, 3})
+	assert.Equal(t, []int{5, 3, 1}, set.GetGreaterOrEqual(10))
+	assert.Equal(t, []int{3, 1}, set.Clone().GetGreater(5))
+}
@@ -0,0 +1,289 @@
+package sortedmap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/exp/constraints"
+)
+
+var (
+	ErrPatchOrder         = errors.New("sortedmap: patch keys are not in the order of the map")
+	ErrPatchKeyExists     = errors.New("sortedmap: key to insert already exists")
+	ErrPatchKeyMissing    = errors.New("sortedmap: key to delete or update does not exist")
+	ErrPatchValueMismatch = errors.New("sortedmap: current value differs from the expected old value")
+	ErrPatchKind          = errors.New("sortedmap: invalid patch operation")
+)
+
+// PatchError reports the operation whose precondition did not hold. When the
+// patch would exceed WithMaxCapacity, Err is ErrMaxCapacity and Op is the last
+// insert.
+type PatchError struct {
+	Op  int
+	Err error
+}
+
+func (e *PatchError) Error() string {
+	return fmt.Sprintf("%v (patch operation %d)", e.Err, e.Op)
+}
+
+func (e *PatchError) Unwrap() error {
+	return e.Err
+}
+
+// PatchOp inserts Key with Value, deletes Key expecting OldValue, or replaces
+// OldValue of Key with Value.
+type PatchOp[K constraints.Ordered, V any] struct {
+	Kind     ChangeKind `json:"op"`
+	Key      K          `json:"key"`
+	Value    V          `json:"value,omitempty"`
+	OldValue V          `json:"oldValue,omitempty"`
+}
+
+// Patch is a list of operations in the key order of the maps it was made
+// from. The order is checked when the patch is applied.
+type Patch[K constraints.Ordered, V any] struct {
+	ops []PatchOp[K, V]
+}
+
+// NewPatch returns the patch turning a into b. Values are compared with eq.
+func NewPatch[K constraints.Ordered, V any](a *NoLockSortedMap[K, V], b *NoLockSortedMap[K, V], eq func(V, V) bool) *Patch[K, V] {
+	p := &Patch[K, V]{}
+	DiffMapFunc(a, b, eq, func(e ChangeEvent[K, V]) bool {
+		op := PatchOp[K, V]{Kind: e.Kind, Key: e.Key}
+		switch e.Kind {
+		case ChangeInsert:
+			op.Value = e.Value
+		case ChangeDelete:
+			op.OldValue = e.Value
+		case ChangeUpdate:
+			op.Value = e.Value
+			op.OldValue = e.OldValue
+		}
+		p.ops = append(p.ops, op)
+		return true
+	})
+	return p
+}
+
+func (p *Patch[K, V]) Len() int {
+	return len(p.ops)
+}
+
+// Ops returns the operations. The returned slice must not be modified.
+func (p *Patch[K, V]) Ops() []PatchOp[K, V] {
+	return p.ops
+}
+
+// Invert returns the patch undoing p.
+func (p *Patch[K, V]) Invert() *Patch[K, V] {
+	ops := make([]PatchOp[K, V], len(p.ops))
+	for i, op := range p.ops {
+		switch op.Kind {
+		case ChangeInsert:
+			op.Kind = ChangeDelete
+		case ChangeDelete:
+			op.Kind = ChangeInsert
+		}
+		op.Value, op.OldValue = op.OldValue, op.Value
+		ops[i] = op
+	}
+	return &Patch[K, V]{ops: ops}
+}
+
+// validate checks the operations against the key order of the target.
+func (p *Patch[K, V]) validate(compare func(K, K) int) error {
+	for i, op := range p.ops {
+		if op.Kind != ChangeInsert && op.Kind != ChangeDelete && op.Kind != ChangeUpdate {
+			return &PatchError{Op: i, Err: ErrPatchKind}
+		}
+		if compare != nil && i > 0 && compare(p.ops[i-1].Key, op.Key) >= 0 {
+			return &PatchError{Op: i, Err: ErrPatchOrder}
+		}
+	}
+	return nil
+}
+
+func (p Patch[K, V]) MarshalJSON() ([]byte, error) {
+	if p.ops == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(p.ops)
+}
+
+func (p *Patch[K, V]) UnmarshalJSON(data []byte) error {
+	var ops []PatchOp[K, V]
+	if err := json.Unmarshal(data, &ops); err != nil {
+		return err
+	}
+	decoded := Patch[K, V]{ops: ops}
+	if err := decoded.validate(nil); err != nil {
+		return err
+	}
+	*p = decoded
+	return nil
+}
+
+// Encode writes p in the checksummed binary format of the snapshots.
+func (p *Patch[K, V]) Encode(w io.Writer, keyCodec Codec[K], valueCodec Codec[V]) (int64, error) {
+	sw := newSnapshotWriter(w)
+	if err := sw.writeHeader(snapshotKindPatch, len(p.ops)); err != nil {
+		return sw.n, err
+	}
+	for _, op := range p.ops {
+		if _, err := sw.Write([]byte{byte(op.Kind)}); err != nil {
+			return sw.n, err
+		}
+		if err := keyCodec.Encode(sw, op.Key); err != nil {
+			return sw.n, err
+		}
+		if op.Kind != ChangeDelete {
+			if err := valueCodec.Encode(sw, op.Value); err != nil {
+				return sw.n, err
+			}
+		}
+		if op.Kind != ChangeInsert {
+			if err := valueCodec.Encode(sw, op.OldValue); err != nil {
+				return sw.n, err
+			}
+		}
+	}
+	return sw.finish()
+}
+
+func DecodePatch[K constraints.Ordered, V any](r io.Reader, keyCodec Codec[K], valueCodec Codec[V]) (*Patch[K, V], int64, error) {
+	sr := newSnapshotReader(r)
+	count, err := sr.readHeader(snapshotKindPatch)
+	if err != nil {
+		return nil, sr.n, err
+	}
+
+	ops := make([]PatchOp[K, V], 0, restoreCapacity(count))
+	for i := 0; i < count; i++ {
+		kind, err := sr.ReadByte()
+		if err != nil {
+			return nil, sr.n, sr.fail(err)
+		}
+		op := PatchOp[K, V]{Kind: ChangeKind(kind)}
+		if op.Kind != ChangeInsert && op.Kind != ChangeDelete && op.Kind != ChangeUpdate {
+			return nil, sr.n, sr.fail(ErrPatchKind)
+		}
+		if op.Key, err = keyCodec.Decode(sr); err != nil {
+			return nil, sr.n, sr.fail(err)
+		}
+		if op.Kind != ChangeDelete {
+			if op.Value, err = valueCodec.Decode(sr); err != nil {
+				return nil, sr.n, sr.fail(err)
+			}
+		}
+		if op.Kind != ChangeInsert {
+			if op.OldValue, err = valueCodec.Decode(sr); err != nil {
+				return nil, sr.n, sr.fail(err)
+			}
+		}
+		ops = append(ops, op)
+	}
+	if _, err := sr.finish(); err != nil {
+		return nil, sr.n, err
+	}
+	return &Patch[K, V]{ops: ops}, sr.n, nil
+}
+
+// ApplyPatch applies p in a single merge pass. When a precondition does not
+// hold, it returns a *PatchError and leaves s unchanged. A nil eq skips the
+// comparison with the old values.
+func (s *NoLockSortedMap[K, V]) ApplyPatch(p *Patch[K, V], eq func(V, V) bool) error {
+	if err := p.validate(s.layout.compare); err != nil {
+		return err
+	}
+
+	positions := make([]int, len(p.ops))
+	size := len(s.keys)
+	lastInsert := -1
+	prev := 0
+	for i, op := range p.ops {
+		off, exists := s.layout.search(s.keys[prev:], op.Key)
+		pos := prev + off
+		switch op.Kind {
+		case ChangeInsert:
+			if exists {
+				return &PatchError{Op: i, Err: ErrPatchKeyExists}
+			}
+			size++
+			lastInsert = i
+		default:
+			if !exists {
+				return &PatchError{Op: i, Err: ErrPatchKeyMissing}
+			}
+			if eq != nil && !eq(s.values[pos], op.OldValue) {
+				return &PatchError{Op: i, Err: ErrPatchValueMismatch}
+			}
+			if op.Kind == ChangeDelete {
+				size--
+			}
+		}
+		positions[i] = pos
+		prev = pos
+	}
+
+	if size > s.layout.limit(size) {
+		return &PatchError{Op: lastInsert, Err: ErrMaxCapacity}
+	}
+	newCap := cap(s.values)
+	if newCap < size {
+		newCap, _ = s.layout.grow(newCap, size)
+		if newCap < size {
+			newCap = size
+		}
+		s.stats.resized()
+	}
+	keys := s.layout.makeKeys(newCap)
+	values := s.layout.makeValues(newCap)
+	inserted, deleted := 0, 0
+	src := 0
+	for i, op := range p.ops {
+		pos := positions[i]
+		keys = append(keys, s.keys[src:pos]...)
+		values = append(values, s.values[src:pos]...)
+		src = pos
+
+		switch op.Kind {
+		case ChangeInsert:
+			if s.hook != nil {
+				s.hook(ChangeEvent[K, V]{Kind: ChangeInsert, Index: len(keys), Key: op.Key, Value: op.Value})
+			}
+			keys = append(keys, op.Key)
+			values = append(values, op.Value)
+			inserted++
+		case ChangeDelete:
+			if s.hook != nil {
+				s.hook(ChangeEvent[K, V]{Kind: ChangeDelete, Index: len(keys), Key: op.Key, Value: s.values[pos]})
+			}
+			src++
+			deleted++
+		case ChangeUpdate:
+			if s.hook != nil {
+				s.hook(ChangeEvent[K, V]{Kind: ChangeUpdate, Index: len(keys), Key: op.Key, Value: op.Value, OldValue: s.values[pos]})
+			}
+			keys = append(keys, op.Key)
+			values = append(values, op.Value)
+			src++
+		}
+	}
+	keys = append(keys, s.keys[src:]...)
+	values = append(values, s.values[src:]...)
+
+	s.keys = keys
+	s.values = values
+	s.stats.insertedN(inserted)
+	s.stats.deleted(deleted)
+	return nil
+}
+
+func (s *SortedMap[K, V]) ApplyPatch(p *Patch[K, V], eq func(V, V) bool) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.s.ApplyPatch(p, eq)
+}
@@ -0,0 +1,133 @@
+package sortedmap_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/sapphi-red/sortedmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPatchMaps() (*sortedmap.NoLockSortedMap[int, string], *sortedmap.NoLockSortedMap[int, string]) {
+	a := sortedmap.NewNoLockSortedMap[int, string](5)
+	a.InsertAll([]int{1, 2, 3, 5}, []string{"1", "2", "3", "5"})
+	b := sortedmap.NewNoLockSortedMap[int, string](5)
+	b.InsertAll([]int{0, 2, 3, 4}, []string{"0", "2", "x", "4"})
+	return a, b
+}
+
+func TestPatch_Apply(t *testing.T) {
+	t.Parallel()
+
+	a, b := newPatchMaps()
+	p := sortedmap.NewPatch(a, b, eqString)
+	assert.Equal(t, 5, p.Len())
+
+	var events []sortedmap.ChangeEvent[int, string]
+	m := sortedmap.NewSortedMap[int, string](5)
+	m.InsertAll([]int{1, 2, 3, 5}, []string{"1", "2", "3", "5"})
+	m.Subscribe(sortedmap.ObserverFuncs[int, string]{
+		Insert: func(index int, key int, value string) {
+			events = append(events, sortedmap.ChangeEvent[int, string]{Kind: sortedmap.ChangeInsert, Index: index, Key: key, Value: value})
+		},
+		Delete: func(index int, key int, value string) {
+			events = append(events, sortedmap.ChangeEvent[int, string]{Kind: sortedmap.ChangeDelete, Index: index, Key: key, Value: value})
+		},
+		Update: func(index int, key int, oldValue string, newValue string) {
+			events = append(events, sortedmap.ChangeEvent[int, string]{Kind: sortedmap.ChangeUpdate, Index: index, Key: key, Value: newValue, OldValue: oldValue})
+		},
+	})
+
+	assert.NoError(t, m.ApplyPatch(p, eqString))
+	assert.Equal(t, b.GetGreaterOrEqual(0), m.GetGreaterOrEqual(0))
+	assert.Equal(t, []sortedmap.ChangeEvent[int, string]{
+		{Kind: sortedmap.ChangeInsert, Index: 0, Key: 0, Value: "0"},
+		{Kind: sortedmap.ChangeDelete, Index: 1, Key: 1, Value: "1"},
+		{Kind: sortedmap.ChangeUpdate, Index: 2, Key: 3, Value: "x", OldValue: "3"},
+		{Kind: sortedmap.ChangeInsert, Index: 3, Key: 4, Value: "4"},
+		{Kind: sortedmap.ChangeDelete, Index: 4, Key: 5, Value: "5"},
+	}, events)
+
+	assert.NoError(t, b.ApplyPatch(p.Invert(), eqString))
+	assert.True(t, sortedmap.EqualMap(a, b, eqString))
+}
+
+func TestPatch_ApplyConflict(t *testing.T) {
+	t.Parallel()
+
+	a, b := newPatchMaps()
+	p := sortedmap.NewPatch(a, b, eqString)
+
+	// the update of 3 does not match, so nothing must be applied
+	a.Delete(3)
+	a.Insert(3, "y")
+	before := append([]string(nil), a.GetGreaterOrEqual(0)...)
+	err := a.ApplyPatch(p, eqString)
+	var patchErr *sortedmap.PatchError
+	assert.ErrorAs(t, err, &patchErr)
+	assert.Equal(t, 2, patchErr.Op)
+	assert.ErrorIs(t, err, sortedmap.ErrPatchValueMismatch)
+	assert.Equal(t, before, a.GetGreaterOrEqual(0))
+
+	assert.NoError(t, a.ApplyPatch(p, nil))
+	assert.True(t, sortedmap.EqualMap(a, b, eqString))
+	assert.ErrorIs(t, a.ApplyPatch(p, nil), sortedmap.ErrPatchKeyExists)
+}
+
+func TestPatch_ApplyMaxCapacity(t *testing.T) {
+	t.Parallel()
+
+	a := sortedmap.NewNoLockSortedMapWithOptions[int, string](sortedmap.WithMaxCapacity(2))
+	a.InsertAll([]int{1, 2}, []string{"1", "2"})
+	b := sortedmap.NewNoLockSortedMap[int, string](5)
+	b.InsertAll([]int{0, 1, 2, 3}, []string{"0", "1", "2", "3"})
+
+	err := a.ApplyPatch(sortedmap.NewPatch(a, b, eqString), eqString)
+	var patchErr *sortedmap.PatchError
+	assert.ErrorAs(t, err, &patchErr)
+	assert.Equal(t, 1, patchErr.Op)
+	assert.ErrorIs(t, err, sortedmap.ErrMaxCapacity)
+	assert.Equal(t, []string{"1", "2"}, a.GetGreaterOrEqual(0))
+}
+
+func TestPatch_JSON(t *testing.T) {
+	t.Parallel()
+
+	a, b := newPatchMaps()
+	p := sortedmap.NewPatch(a, b, eqString)
+
+	data, err := json.Marshal(p)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `{"op":"update","key":3,"value":"x","oldValue":"3"}`)
+
+	var decoded sortedmap.Patch[int, string]
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, p.Ops(), decoded.Ops())
+
+	assert.NoError(t, json.Unmarshal([]byte(`[{"op":"insert","key":2},{"op":"delete","key":1}]`), &decoded))
+	assert.ErrorIs(t, a.ApplyPatch(&decoded, nil), sortedmap.ErrPatchOrder)
+	assert.Error(t, json.Unmarshal([]byte(`[{"op":"move","key":1}]`), &decoded))
+}
+
+func TestPatch_Binary(t *testing.T) {
+	t.Parallel()
+
+	a, b := newPatchMaps()
+	p := sortedmap.NewPatch(a, b, eqString)
+
+	var buf bytes.Buffer
+	n, err := p.Encode(&buf, sortedmap.OrderedCodec[int](), sortedmap.OrderedCodec[string]())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+
+	decoded, m, err := sortedmap.DecodePatch(bytes.NewReader(buf.Bytes()), sortedmap.OrderedCodec[int](), sortedmap.OrderedCodec[string]())
+	assert.NoError(t, err)
+	assert.Equal(t, n, m)
+	assert.Equal(t, p.Ops(), decoded.Ops())
+
+	data := buf.Bytes()
+	data[len(data)-1] ^= 0xff
+	_, _, err = sortedmap.DecodePatch(bytes.NewReader(data), sortedmap.OrderedCodec[int](), sortedmap.OrderedCodec[string]())
+	assert.ErrorIs(t, err, sortedmap.ErrSnapshotChecksum)
+}
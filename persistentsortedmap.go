@@ -0,0 +1,419 @@
+package sortedmap
+
+import (
+	"golang.org/x/exp/constraints"
+	"golang.org/x/exp/slices"
+)
+
+// persistentNodeSize is the maximum number of entries of a leaf and of
+// children of an inner node. A node left with less than a quarter of it is
+// merged with a neighbor.
+const persistentNodeSize = 64
+
+// persistentNode is a node of a B+ tree and is never modified once built.
+// Leaves hold the entries. Inner nodes hold the children with the last key
+// and the number of entries of each, so that positions can be counted on
+// the way down.
+type persistentNode[K constraints.Ordered, V any] struct {
+	keys     []K
+	values   []V
+	children []*persistentNode[K, V]
+	counts   []int
+}
+
+func copyWith[T any](s []T, extra int) []T {
+	return append(make([]T, 0, len(s)+extra), s...)
+}
+
+func (n *persistentNode[K, V]) leaf() bool {
+	return n.children == nil
+}
+
+func (n *persistentNode[K, V]) lastKey() K {
+	return n.keys[len(n.keys)-1]
+}
+
+func (n *persistentNode[K, V]) size() int {
+	if n.leaf() {
+		return len(n.keys)
+	}
+	return n.before(len(n.counts))
+}
+
+// before returns the number of entries of the children before children[i].
+func (n *persistentNode[K, V]) before(i int) int {
+	pos := 0
+	for _, c := range n.counts[:i] {
+		pos += c
+	}
+	return pos
+}
+
+func (n *persistentNode[K, V]) copyInner(extra int) *persistentNode[K, V] {
+	return &persistentNode[K, V]{
+		keys:     copyWith(n.keys, extra),
+		children: copyWith(n.children, extra),
+		counts:   copyWith(n.counts, extra),
+	}
+}
+
+func (n *persistentNode[K, V]) setChild(i int, child *persistentNode[K, V]) {
+	n.children[i] = child
+	n.keys[i] = child.lastKey()
+	n.counts[i] = child.size()
+}
+
+func (n *persistentNode[K, V]) insertChild(i int, child *persistentNode[K, V]) {
+	n.children = insertAt(n.children, i, child)
+	n.keys = insertAt(n.keys, i, child.lastKey())
+	n.counts = insertAt(n.counts, i, child.size())
+}
+
+func (n *persistentNode[K, V]) removeChild(i int) {
+	n.children = deleteAt(n.children, i)
+	n.keys = deleteAt(n.keys, i)
+	n.counts = deleteAt(n.counts, i)
+}
+
+// split halves a node which grew beyond persistentNodeSize.
+func (n *persistentNode[K, V]) split() (*persistentNode[K, V], *persistentNode[K, V]) {
+	if len(n.keys) <= persistentNodeSize {
+		return n, nil
+	}
+	half := len(n.keys) / 2
+	left := &persistentNode[K, V]{keys: n.keys[:half:half]}
+	right := &persistentNode[K, V]{keys: n.keys[half:]}
+	if n.leaf() {
+		left.values, right.values = n.values[:half:half], n.values[half:]
+	} else {
+		left.children, right.children = n.children[:half:half], n.children[half:]
+		left.counts, right.counts = n.counts[:half:half], n.counts[half:]
+	}
+	return left, right
+}
+
+// mergeChildren merges children[i] and children[i+1], splitting the result
+// again if it is too large.
+func (n *persistentNode[K, V]) mergeChildren(i int) {
+	a, b := n.children[i], n.children[i+1]
+	m := &persistentNode[K, V]{keys: append(copyWith(a.keys, len(b.keys)), b.keys...)}
+	if a.leaf() {
+		m.values = append(copyWith(a.values, len(b.values)), b.values...)
+	} else {
+		m.children = append(copyWith(a.children, len(b.children)), b.children...)
+		m.counts = append(copyWith(a.counts, len(b.counts)), b.counts...)
+	}
+	left, right := m.split()
+	n.setChild(i, left)
+	if right != nil {
+		n.setChild(i+1, right)
+	} else {
+		n.removeChild(i + 1)
+	}
+}
+
+// insert returns the new node, the node split off it if it grew too large,
+// and the position of key in the node, or -1 if key exists.
+func (n *persistentNode[K, V]) insert(key K, value V) (*persistentNode[K, V], *persistentNode[K, V], int) {
+	i, exists := slices.BinarySearch(n.keys, key)
+	if n.leaf() {
+		if exists {
+			return n, nil, -1
+		}
+		c := &persistentNode[K, V]{
+			keys:   insertAt(copyWith(n.keys, 1), i, key),
+			values: insertAt(copyWith(n.values, 1), i, value),
+		}
+		left, right := c.split()
+		return left, right, i
+	}
+
+	if i == len(n.keys) {
+		i-- // beyond the last key, which the last child takes
+	}
+	child, sibling, pos := n.children[i].insert(key, value)
+	if pos < 0 {
+		return n, nil, -1
+	}
+	c := n.copyInner(1)
+	c.setChild(i, child)
+	if sibling != nil {
+		c.insertChild(i+1, sibling)
+	}
+	left, right := c.split()
+	return left, right, n.before(i) + pos
+}
+
+// delete returns the new node, or nil if it became empty, and the position
+// of key in the node, or -1 if key does not exist.
+func (n *persistentNode[K, V]) delete(key K) (*persistentNode[K, V], int) {
+	i, exists := slices.BinarySearch(n.keys, key)
+	if n.leaf() {
+		if !exists {
+			return n, -1
+		}
+		if len(n.keys) == 1 {
+			return nil, i
+		}
+		return &persistentNode[K, V]{
+			keys:   deleteAt(copyWith(n.keys, 0), i),
+			values: deleteAt(copyWith(n.values, 0), i),
+		}, i
+	}
+
+	if i == len(n.keys) {
+		return n, -1
+	}
+	child, pos := n.children[i].delete(key)
+	if pos < 0 {
+		return n, -1
+	}
+	pos += n.before(i)
+	c := n.copyInner(0)
+	if child == nil {
+		c.removeChild(i)
+		if len(c.keys) == 0 {
+			return nil, pos
+		}
+		return c, pos
+	}
+	c.setChild(i, child)
+	if len(child.keys) < persistentNodeSize/4 && len(c.children) > 1 {
+		if i+1 == len(c.children) {
+			i--
+		}
+		c.mergeChildren(i)
+	}
+	return c, pos
+}
+
+// appendValues appends the values whose positions in the node are in
+// [start, end).
+func (n *persistentNode[K, V]) appendValues(res []V, start int, end int) []V {
+	if n.leaf() {
+		return append(res, n.values[start:end]...)
+	}
+	for i, count := range n.counts {
+		if start < count && end > 0 {
+			from := start
+			if from < 0 {
+				from = 0
+			}
+			res = n.children[i].appendValues(res, from, minInt(end, count))
+		}
+		start -= count
+		end -= count
+		if end <= 0 {
+			break
+		}
+	}
+	return res
+}
+
+func (n *persistentNode[K, V]) rangeEntries(fn func(key K, value V) bool) bool {
+	if n.leaf() {
+		for i := range n.keys {
+			if !fn(n.keys[i], n.values[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, child := range n.children {
+		if !child.rangeEntries(fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// PersistentSortedMap is an immutable sorted map. Insert and Delete return a
+// new version which copies only the path to the changed leaf of a B+ tree
+// and shares every other node with the receiver, so a version can be read
+// forever without any locking.
+//
+// It has the query API of NoLockSortedMap, but its mutating methods return
+// the new version. It has no capacity to manage, so Capacity and
+// ExtendCapacityTo are left out.
+type PersistentSortedMap[K constraints.Ordered, V any] struct {
+	root *persistentNode[K, V] // nil when empty
+	size int
+}
+
+func NewPersistentSortedMap[K constraints.Ordered, V any]() *PersistentSortedMap[K, V] {
+	return &PersistentSortedMap[K, V]{}
+}
+
+func (s *PersistentSortedMap[K, V]) Size() int {
+	return s.size
+}
+
+// Clear returns an empty version.
+func (s *PersistentSortedMap[K, V]) Clear() *PersistentSortedMap[K, V] {
+	return NewPersistentSortedMap[K, V]()
+}
+
+// search returns the number of keys less than key and whether key exists.
+func (s *PersistentSortedMap[K, V]) search(key K) (int, bool) {
+	pos := 0
+	for n := s.root; n != nil; {
+		i, exists := slices.BinarySearch(n.keys, key)
+		if n.leaf() {
+			return pos + i, exists
+		}
+		if i == len(n.keys) {
+			return s.size, false
+		}
+		pos += n.before(i)
+		n = n.children[i]
+	}
+	return 0, false
+}
+
+func (s *PersistentSortedMap[K, V]) Insert(key K, value V) (*PersistentSortedMap[K, V], int) {
+	if s.root == nil {
+		leaf := &persistentNode[K, V]{keys: []K{key}, values: []V{value}}
+		return &PersistentSortedMap[K, V]{root: leaf, size: 1}, 0
+	}
+
+	left, right, pos := s.root.insert(key, value)
+	if pos < 0 {
+		return s, -1
+	}
+	root := left
+	if right != nil {
+		root = &persistentNode[K, V]{
+			keys:     []K{left.lastKey(), right.lastKey()},
+			children: []*persistentNode[K, V]{left, right},
+			counts:   []int{left.size(), right.size()},
+		}
+	}
+	return &PersistentSortedMap[K, V]{root: root, size: s.size + 1}, pos
+}
+
+// InsertWithAfterHint is Insert. The tree search does not need the hint,
+// which is accepted so that code written for NoLockSortedMap keeps working.
+func (s *PersistentSortedMap[K, V]) InsertWithAfterHint(key K, value V, afterIndex int) (*PersistentSortedMap[K, V], int) {
+	return s.Insert(key, value)
+}
+
+func (s *PersistentSortedMap[K, V]) Delete(key K) (*PersistentSortedMap[K, V], int) {
+	if s.root == nil {
+		return s, -1
+	}
+
+	root, pos := s.root.delete(key)
+	if pos < 0 {
+		return s, -1
+	}
+	for root != nil && !root.leaf() && len(root.children) == 1 {
+		root = root.children[0]
+	}
+	return &PersistentSortedMap[K, V]{root: root, size: s.size - 1}, pos
+}
+
+// DeleteWithAfterHint is Delete, see InsertWithAfterHint.
+func (s *PersistentSortedMap[K, V]) DeleteWithAfterHint(key K, afterIndex int) (*PersistentSortedMap[K, V], int) {
+	return s.Delete(key)
+}
+
+func (s *PersistentSortedMap[K, V]) InsertAll(keys []K, values []V) *PersistentSortedMap[K, V] {
+	for i := range keys {
+		s, _ = s.Insert(keys[i], values[i])
+	}
+	return s
+}
+
+func (s *PersistentSortedMap[K, V]) InsertAllByMap(m map[K]V) *PersistentSortedMap[K, V] {
+	for k, v := range m {
+		s, _ = s.Insert(k, v)
+	}
+	return s
+}
+
+func (s *PersistentSortedMap[K, V]) InsertAllOrdered(keys []K, values []V) *PersistentSortedMap[K, V] {
+	return s.InsertAll(keys, values)
+}
+
+func (s *PersistentSortedMap[K, V]) DeleteAll(keys []K) *PersistentSortedMap[K, V] {
+	for i := range keys {
+		s, _ = s.Delete(keys[i])
+	}
+	return s
+}
+
+func (s *PersistentSortedMap[K, V]) DeleteAllOrdered(keys []K) *PersistentSortedMap[K, V] {
+	return s.DeleteAll(keys)
+}
+
+func (s *PersistentSortedMap[K, V]) Contains(key K) bool {
+	_, exists := s.search(key)
+	return exists
+}
+
+func (s *PersistentSortedMap[K, V]) Get(key K) (V, bool) {
+	for n := s.root; n != nil; {
+		i, exists := slices.BinarySearch(n.keys, key)
+		if n.leaf() {
+			if exists {
+				return n.values[i], true
+			}
+			break
+		}
+		if i == len(n.keys) {
+			break
+		}
+		n = n.children[i]
+	}
+	var zero V
+	return zero, false
+}
+
+func (s *PersistentSortedMap[K, V]) GetIndexOfGreater(key K) int {
+	pos, exists := s.search(key)
+	if exists {
+		pos++ // does not include multiple same values
+	}
+	return pos
+}
+func (s *PersistentSortedMap[K, V]) GetIndexOfGreaterOrEqual(key K) int {
+	pos, _ := s.search(key)
+	return pos
+}
+
+// valuesBetween copies the values whose indexes are in [start, end).
+func (s *PersistentSortedMap[K, V]) valuesBetween(start int, end int) []V {
+	if end <= start {
+		return []V{}
+	}
+	return s.root.appendValues(make([]V, 0, end-start), start, end)
+}
+
+func (s *PersistentSortedMap[K, V]) GetGreater(key K) []V {
+	pos := s.GetIndexOfGreater(key)
+	return s.valuesBetween(pos, s.size)
+}
+func (s *PersistentSortedMap[K, V]) GetGreaterOrEqual(key K) []V {
+	pos := s.GetIndexOfGreaterOrEqual(key)
+	return s.valuesBetween(pos, s.size)
+}
+func (s *PersistentSortedMap[K, V]) GetLess(key K) []V {
+	pos := s.GetIndexOfGreaterOrEqual(key)
+	return s.valuesBetween(0, pos)
+}
+func (s *PersistentSortedMap[K, V]) GetLessOrEqual(key K) []V {
+	pos := s.GetIndexOfGreater(key)
+	return s.valuesBetween(0, pos)
+}
+
+func (s *PersistentSortedMap[K, V]) GetByInclusiveRange(startKey K, endKey K) []V {
+	startPos := s.GetIndexOfGreaterOrEqual(startKey)
+	endPos := s.GetIndexOfGreater(endKey)
+	return s.valuesBetween(startPos, endPos)
+}
+
+func (s *PersistentSortedMap[K, V]) Range(fn func(key K, value V) bool) {
+	if s.root != nil {
+		s.root.rangeEntries(fn)
+	}
+}
@@ -0,0 +1,113 @@
+package sortedmap_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/sapphi-red/sortedmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPersistentSortedMap_Snapshot(t *testing.T) {
+	t.Parallel()
+
+	v0 := sortedmap.NewPersistentSortedMap[int, string]()
+	v1, res := v0.Insert(1, "1")
+	assert.Equal(t, 0, res)
+	v2, res := v1.Insert(3, "3")
+	assert.Equal(t, 1, res)
+	v3, res := v2.Delete(1)
+	assert.Equal(t, 0, res)
+
+	_, res = v3.Delete(1)
+	assert.Equal(t, -1, res)
+	same, res := v2.Insert(3, "dup")
+	assert.Equal(t, -1, res)
+	assert.Equal(t, v2, same)
+
+	assert.Equal(t, 0, v0.Size())
+	assert.Equal(t, []string{"1"}, v1.GetGreaterOrEqual(0))
+	assert.Equal(t, []string{"1", "3"}, v2.GetGreaterOrEqual(0))
+	assert.Equal(t, []string{"3"}, v3.GetGreaterOrEqual(0))
+
+	value, ok := v2.Get(3)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "3", value)
+	_, ok = v3.Get(1)
+	assert.Equal(t, false, ok)
+}
+
+func TestPersistentSortedMap_MatchesNoLockSortedMap(t *testing.T) {
+	t.Parallel()
+
+	rnd := rand.New(rand.NewSource(1))
+	expected := sortedmap.NewNoLockSortedMap[int, int](0)
+	actual := sortedmap.NewPersistentSortedMap[int, int]()
+	var versions []*sortedmap.PersistentSortedMap[int, int]
+	var versionValues [][]int
+	// grow the tree to three levels, then shrink it so that nodes are merged
+	for i := 0; i < 40000; i++ {
+		key := rnd.Intn(10000)
+		deleting := rnd.Intn(4) == 0
+		if i >= 20000 {
+			deleting = !deleting
+		}
+		var res int
+		if deleting {
+			actual, res = actual.Delete(key)
+			assert.Equal(t, expected.Delete(key), res)
+		} else {
+			actual, res = actual.Insert(key, i)
+			assert.Equal(t, expected.Insert(key, i), res)
+		}
+		if i%4000 == 0 {
+			versions = append(versions, actual)
+			versionValues = append(versionValues, append([]int(nil), expected.GetGreaterOrEqual(-1)...))
+		}
+	}
+
+	// older versions are not affected by later changes
+	for i, v := range versions {
+		assert.Equal(t, versionValues[i], v.GetGreaterOrEqual(-1))
+		assert.Equal(t, len(versionValues[i]), v.Size())
+	}
+
+	assert.Equal(t, expected.Size(), actual.Size())
+	for key := -1; key <= 1001; key += 7 {
+		assert.Equal(t, expected.Contains(key), actual.Contains(key))
+		assert.Equal(t, expected.GetIndexOfGreater(key), actual.GetIndexOfGreater(key))
+		assert.Equal(t, expected.GetIndexOfGreaterOrEqual(key), actual.GetIndexOfGreaterOrEqual(key))
+		assert.Equal(t, expected.GetGreater(key), actual.GetGreater(key))
+		assert.Equal(t, expected.GetLessOrEqual(key), actual.GetLessOrEqual(key))
+		assert.Equal(t, expected.GetByInclusiveRange(key, key+100), actual.GetByInclusiveRange(key, key+100))
+	}
+
+	var values []int
+	actual.Range(func(key int, value int) bool {
+		values = append(values, value)
+		return true
+	})
+	assert.Equal(t, expected.GetGreaterOrEqual(-1), values)
+}
+
+func TestPersistentSortedMap_Batch(t *testing.T) {
+	t.Parallel()
+
+	v0 := sortedmap.NewPersistentSortedMap[int, string]()
+	v1 := v0.InsertAll([]int{3, 1, 2}, []string{"3", "1", "2"})
+	v2 := v1.InsertAllOrdered([]int{4, 5}, []string{"4", "5"})
+	v3 := v2.InsertAllByMap(map[int]string{0: "0", 6: "6"})
+	v4 := v3.DeleteAll([]int{6, 0})
+	v5 := v4.DeleteAllOrdered([]int{1, 2})
+	assert.Equal(t, []string{"1", "2", "3"}, v1.GetGreaterOrEqual(0))
+	assert.Equal(t, []string{"0", "1", "2", "3", "4", "5", "6"}, v3.GetGreaterOrEqual(0))
+	assert.Equal(t, []string{"1", "2", "3", "4", "5"}, v4.GetGreaterOrEqual(0))
+	assert.Equal(t, []string{"3", "4", "5"}, v5.GetGreaterOrEqual(0))
+
+	v6, res := v5.InsertWithAfterHint(1, "1", 0)
+	assert.Equal(t, 0, res)
+	_, res = v6.DeleteWithAfterHint(4, 1)
+	assert.Equal(t, 2, res)
+	assert.Equal(t, 0, v6.Clear().Size())
+	assert.Equal(t, 4, v6.Size())
+}
@@ -0,0 +1,220 @@
+package sortedmap
+
+import "golang.org/x/exp/constraints"
+
+// KeyPrefix selects the string keys starting with a prefix. It is made by
+// Prefix and works on containers in byte-wise order, either ascending or
+// descending, such as the natural order of strings. The prefix queries panic
+// on containers created WithComparator, whose order they cannot follow.
+type KeyPrefix[K constraints.Ordered] struct {
+	start K
+	above K // start + "\x00", only used to tell the order of the container
+	// end is the least key above all the prefixed ones, which does not exist
+	// when the prefix is empty or made of 0xFF bytes only.
+	end     K
+	bounded bool
+}
+
+// Prefix selects the keys starting with prefix. The upper bound is computed
+// on bytes, so it does not depend on prefix being valid UTF-8.
+func Prefix[K ~string](prefix K) KeyPrefix[K] {
+	p := KeyPrefix[K]{start: prefix, above: prefix + "\x00"}
+	end := []byte(prefix)
+	for len(end) > 0 && end[len(end)-1] == 0xFF {
+		end = end[:len(end)-1]
+	}
+	if len(end) > 0 {
+		end[len(end)-1]++
+		p.end = K(end)
+		p.bounded = true
+	}
+	return p
+}
+
+// prefixBetween returns the positions [lo, hi) of the keys with the prefix.
+func prefixBetween[K constraints.Ordered, V any](l *layout[K, V], keys []K, p KeyPrefix[K]) (int, int) {
+	if l != nil && l.custom {
+		panic("sortedmap: prefix queries cannot be used with a custom comparator")
+	}
+	if l.compare(p.start, p.above) <= 0 {
+		lo, _ := l.search(keys, p.start)
+		hi := len(keys)
+		if p.bounded {
+			hi, _ = l.search(keys, p.end)
+		}
+		return lo, hi
+	}
+
+	// In descending order the prefixed keys follow end and precede start.
+	lo := 0
+	if p.bounded {
+		lo = indexOfGreater(l, keys, p.end)
+	}
+	return lo, indexOfGreater(l, keys, p.start)
+}
+
+func indexOfGreater[K constraints.Ordered, V any](l *layout[K, V], keys []K, key K) int {
+	pos, exists := l.search(keys, key)
+	if exists {
+		pos++
+	}
+	return pos
+}
+
+func (s *NoLockSortedSet[K]) GetByPrefix(p KeyPrefix[K]) []K {
+	lo, hi := prefixBetween(s.layout, s.values, p)
+	res := s.values[lo:hi]
+	s.stats.rangeSize(len(res))
+	return res
+}
+
+func (s *NoLockSortedSet[K]) CountPrefix(p KeyPrefix[K]) int {
+	lo, hi := prefixBetween(s.layout, s.values, p)
+	return hi - lo
+}
+
+// DeletePrefix deletes the entries whose key starts with the prefix and
+// returns their number.
+func (s *NoLockSortedSet[K]) DeletePrefix(p KeyPrefix[K]) int {
+	lo, hi := prefixBetween(s.layout, s.values, p)
+	s.deleteBetween(lo, hi)
+	return hi - lo
+}
+
+// RangePrefix calls fn in order for the entries whose key starts with the
+// prefix, until fn returns false.
+func (s *NoLockSortedSet[K]) RangePrefix(p KeyPrefix[K], fn func(value K) bool) {
+	lo, hi := prefixBetween(s.layout, s.values, p)
+	for i := lo; i < hi; i++ {
+		if !fn(s.values[i]) {
+			return
+		}
+	}
+}
+
+func (s *SortedSet[K]) GetByPrefix(p KeyPrefix[K]) []K {
+	s.m.RLock()
+	defer s.m.RUnlock()
+	return s.s.GetByPrefix(p)
+}
+
+func (s *SortedSet[K]) CountPrefix(p KeyPrefix[K]) int {
+	s.m.RLock()
+	defer s.m.RUnlock()
+	return s.s.CountPrefix(p)
+}
+
+func (s *SortedSet[K]) DeletePrefix(p KeyPrefix[K]) int {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.s.DeletePrefix(p)
+}
+
+// RangePrefix holds the read lock while calling fn, so fn must not modify s.
+func (s *SortedSet[K]) RangePrefix(p KeyPrefix[K], fn func(value K) bool) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+	s.s.RangePrefix(p, fn)
+}
+
+func (s *NoLockSortedMap[K, V]) GetByPrefix(p KeyPrefix[K]) []V {
+	lo, hi := prefixBetween(s.layout, s.keys, p)
+	res := s.values[lo:hi]
+	s.stats.rangeSize(len(res))
+	return res
+}
+
+func (s *NoLockSortedMap[K, V]) CountPrefix(p KeyPrefix[K]) int {
+	lo, hi := prefixBetween(s.layout, s.keys, p)
+	return hi - lo
+}
+
+func (s *NoLockSortedMap[K, V]) DeletePrefix(p KeyPrefix[K]) int {
+	lo, hi := prefixBetween(s.layout, s.keys, p)
+	s.deleteBetween(lo, hi)
+	return hi - lo
+}
+
+func (s *NoLockSortedMap[K, V]) RangePrefix(p KeyPrefix[K], fn func(key K, value V) bool) {
+	lo, hi := prefixBetween(s.layout, s.keys, p)
+	for i := lo; i < hi; i++ {
+		if !fn(s.keys[i], s.values[i]) {
+			return
+		}
+	}
+}
+
+func (s *SortedMap[K, V]) GetByPrefix(p KeyPrefix[K]) []V {
+	s.m.RLock()
+	defer s.m.RUnlock()
+	return s.s.GetByPrefix(p)
+}
+
+func (s *SortedMap[K, V]) CountPrefix(p KeyPrefix[K]) int {
+	s.m.RLock()
+	defer s.m.RUnlock()
+	return s.s.CountPrefix(p)
+}
+
+func (s *SortedMap[K, V]) DeletePrefix(p KeyPrefix[K]) int {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.s.DeletePrefix(p)
+}
+
+func (s *SortedMap[K, V]) RangePrefix(p KeyPrefix[K], fn func(key K, value V) bool) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+	s.s.RangePrefix(p, fn)
+}
+
+func (s *NoLockSortedMapCalc[K, V]) GetByPrefix(p KeyPrefix[K]) []V {
+	lo, hi := prefixBetween(s.layout, s.keys, p)
+	res := s.values[lo:hi]
+	s.stats.rangeSize(len(res))
+	return res
+}
+
+func (s *NoLockSortedMapCalc[K, V]) CountPrefix(p KeyPrefix[K]) int {
+	lo, hi := prefixBetween(s.layout, s.keys, p)
+	return hi - lo
+}
+
+func (s *NoLockSortedMapCalc[K, V]) DeletePrefix(p KeyPrefix[K]) int {
+	lo, hi := prefixBetween(s.layout, s.keys, p)
+	s.deleteBetween(lo, hi)
+	return hi - lo
+}
+
+func (s *NoLockSortedMapCalc[K, V]) RangePrefix(p KeyPrefix[K], fn func(key K, value V) bool) {
+	lo, hi := prefixBetween(s.layout, s.keys, p)
+	for i := lo; i < hi; i++ {
+		if !fn(s.keys[i], s.values[i]) {
+			return
+		}
+	}
+}
+
+func (s *SortedMapCalc[K, V]) GetByPrefix(p KeyPrefix[K]) []V {
+	s.m.RLock()
+	defer s.m.RUnlock()
+	return s.s.GetByPrefix(p)
+}
+
+func (s *SortedMapCalc[K, V]) CountPrefix(p KeyPrefix[K]) int {
+	s.m.RLock()
+	defer s.m.RUnlock()
+	return s.s.CountPrefix(p)
+}
+
+func (s *SortedMapCalc[K, V]) DeletePrefix(p KeyPrefix[K]) int {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.s.DeletePrefix(p)
+}
+
+func (s *SortedMapCalc[K, V]) RangePrefix(p KeyPrefix[K], fn func(key K, value V) bool) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+	s.s.RangePrefix(p, fn)
+}
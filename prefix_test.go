@@ -0,0 +1,117 @@
+package sortedmap_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sapphi-red/sortedmap"
+	"github.com/stretchr/testify/assert"
+)
+
+var prefixWords = []string{"", "a", "ab", "abc", "abd", "ac", "b", "caf", "café", "cafés", "caff", "\xff", "\xff\xff", "\xff\xffa", "a\xff", "a\xff\xff", "b\x00"}
+
+func TestNoLockSortedSet_GetByPrefix(t *testing.T) {
+	t.Parallel()
+
+	set := sortedmap.NewNoLockSortedSet[string](0)
+	set.InsertAll(prefixWords)
+	desc := sortedmap.NewNoLockSortedSetWithOptions[string](sortedmap.WithDescending())
+	desc.InsertAll(prefixWords)
+
+	for _, prefix := range append(prefixWords, "caf\xc3", "z", "a\xff\xff\xff") {
+		var expected []string
+		for _, w := range set.GetGreaterOrEqual("") {
+			if strings.HasPrefix(w, prefix) {
+				expected = append(expected, w)
+			}
+		}
+		p := sortedmap.Prefix(prefix)
+		assert.Equal(t, len(expected), set.CountPrefix(p), prefix)
+		assert.Equal(t, len(expected), desc.CountPrefix(p), prefix)
+		if len(expected) == 0 {
+			assert.Empty(t, set.GetByPrefix(p), prefix)
+			assert.Empty(t, desc.GetByPrefix(p), prefix)
+			continue
+		}
+		assert.Equal(t, expected, set.GetByPrefix(p), prefix)
+
+		var reversed []string
+		for i := len(expected) - 1; i >= 0; i-- {
+			reversed = append(reversed, expected[i])
+		}
+		assert.Equal(t, reversed, desc.GetByPrefix(p), prefix)
+	}
+}
+
+func TestSortedSet_DeletePrefix(t *testing.T) {
+	t.Parallel()
+
+	set := sortedmap.NewSortedSet[string](0)
+	set.InsertAll(prefixWords)
+
+	assert.Equal(t, 4, set.DeletePrefix(sortedmap.Prefix("caf")))
+	assert.Equal(t, 0, set.CountPrefix(sortedmap.Prefix("caf")))
+	assert.Equal(t, 3, set.DeletePrefix(sortedmap.Prefix("\xff")))
+	assert.Equal(t, []string{"b", "b\x00"}, set.GetByPrefix(sortedmap.Prefix("b")))
+
+	var visited []string
+	set.RangePrefix(sortedmap.Prefix("a"), func(value string) bool {
+		visited = append(visited, value)
+		return len(visited) < 3
+	})
+	assert.Equal(t, []string{"a", "ab", "abc"}, visited)
+}
+
+func TestSortedMap_GetByPrefix(t *testing.T) {
+	t.Parallel()
+
+	type path string
+	m := sortedmap.NewSortedMap[path, int](0)
+	for i, p := range []path{"/usr/bin", "/usr/lib", "/usr", "/var/log", "/usr0"} {
+		m.Insert(p, i)
+	}
+
+	assert.Equal(t, []int{0, 1}, m.GetByPrefix(sortedmap.Prefix[path]("/usr/")))
+	assert.Equal(t, 4, m.CountPrefix(sortedmap.Prefix[path]("/usr")))
+
+	var keys []path
+	m.RangePrefix(sortedmap.Prefix[path]("/usr/"), func(key path, value int) bool {
+		keys = append(keys, key)
+		return true
+	})
+	assert.Equal(t, []path{"/usr/bin", "/usr/lib"}, keys)
+
+	assert.Equal(t, 2, m.DeletePrefix(sortedmap.Prefix[path]("/usr/")))
+	assert.Equal(t, 3, m.CountPrefix(sortedmap.Prefix[path]("")))
+}
+
+func TestSortedMapCalc_DeletePrefix(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMapCalc(0, func(v string) string { return strings.ToLower(v) })
+	m.InsertAll([]string{"Apple", "apricot", "Banana"})
+
+	assert.Equal(t, []string{"Apple", "apricot"}, m.GetByPrefix(sortedmap.Prefix("ap")))
+	assert.Equal(t, 2, m.DeletePrefix(sortedmap.Prefix("a")))
+	assert.Equal(t, []string{"Banana"}, m.GetByPrefix(sortedmap.Prefix("")))
+}
+
+func TestSortedSet_PrefixWithComparator(t *testing.T) {
+	t.Parallel()
+
+	set := sortedmap.NewSortedSetWithOptions[string](sortedmap.WithComparator(func(a string, b string) int {
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	}))
+	set.InsertAll([]string{"Apple", "apricot", "Banana"})
+
+	assert.Panics(t, func() {
+		set.GetByPrefix(sortedmap.Prefix("ap"))
+	})
+	assert.Panics(t, func() {
+		set.DeletePrefix(sortedmap.Prefix("ap"))
+	})
+	// the locks are released by the panics
+	assert.Equal(t, 3, set.Size())
+	set.Insert("cherry")
+	assert.Equal(t, 4, set.Size())
+}
@@ -0,0 +1,141 @@
+package sortedmap
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/exp/constraints"
+)
+
+// RCUSortedMap is a read-optimized map. Readers load the current version
+// without locking while writers copy it, apply their changes and publish the
+// result. Use Update to amortize the copy over many changes.
+type RCUSortedMap[K constraints.Ordered, V any] struct {
+	p atomic.Value // *NoLockSortedMap[K, V]
+	m sync.Mutex
+}
+
+func NewRCUSortedMap[K constraints.Ordered, V any](capacity int) *RCUSortedMap[K, V] {
+	s := &RCUSortedMap[K, V]{}
+	s.p.Store(NewNoLockSortedMap[K, V](capacity))
+	return s
+}
+
+// Load returns the current version, which later changes do not affect.
+func (s *RCUSortedMap[K, V]) Load() ReadOnlySortedMap[K, V] {
+	return s.load()
+}
+
+func (s *RCUSortedMap[K, V]) load() *NoLockSortedMap[K, V] {
+	return s.p.Load().(*NoLockSortedMap[K, V])
+}
+
+// Update applies fn to a private copy of the map and publishes it once fn
+// returns. Readers never observe a partially applied update.
+func (s *RCUSortedMap[K, V]) Update(fn func(m *NoLockSortedMap[K, V])) {
+	s.m.Lock()
+	next := s.load().clone(0)
+	fn(next)
+	s.p.Store(next)
+	s.m.Unlock()
+}
+
+func (s *RCUSortedMap[K, V]) update(extraCapacity int, fn func(m *NoLockSortedMap[K, V]) int) int {
+	s.m.Lock()
+	next := s.load().clone(extraCapacity)
+	res := fn(next)
+	s.p.Store(next)
+	s.m.Unlock()
+	return res
+}
+
+func (s *RCUSortedMap[K, V]) Size() int {
+	return s.load().Size()
+}
+
+func (s *RCUSortedMap[K, V]) Clear() {
+	s.m.Lock()
+	s.p.Store(NewNoLockSortedMap[K, V](0))
+	s.m.Unlock()
+}
+
+func (s *RCUSortedMap[K, V]) Insert(key K, value V) int {
+	if s.Contains(key) {
+		return -1
+	}
+	return s.update(1, func(m *NoLockSortedMap[K, V]) int {
+		return m.Insert(key, value)
+	})
+}
+
+func (s *RCUSortedMap[K, V]) Delete(key K) int {
+	if !s.Contains(key) {
+		return -1
+	}
+	return s.update(0, func(m *NoLockSortedMap[K, V]) int {
+		return m.Delete(key)
+	})
+}
+
+func (s *RCUSortedMap[K, V]) InsertAll(keys []K, values []V) {
+	s.update(len(keys), func(m *NoLockSortedMap[K, V]) int {
+		m.InsertAll(keys, values)
+		return 0
+	})
+}
+
+func (s *RCUSortedMap[K, V]) InsertAllByMap(m map[K]V) {
+	s.update(len(m), func(next *NoLockSortedMap[K, V]) int {
+		next.InsertAllByMap(m)
+		return 0
+	})
+}
+
+func (s *RCUSortedMap[K, V]) InsertAllOrdered(keys []K, values []V) {
+	s.update(len(keys), func(m *NoLockSortedMap[K, V]) int {
+		m.InsertAllOrdered(keys, values)
+		return 0
+	})
+}
+
+func (s *RCUSortedMap[K, V]) DeleteAll(keys []K) {
+	s.update(0, func(m *NoLockSortedMap[K, V]) int {
+		m.DeleteAll(keys)
+		return 0
+	})
+}
+
+func (s *RCUSortedMap[K, V]) DeleteAllOrdered(keys []K) {
+	s.update(0, func(m *NoLockSortedMap[K, V]) int {
+		m.DeleteAllOrdered(keys)
+		return 0
+	})
+}
+
+func (s *RCUSortedMap[K, V]) Contains(key K) bool {
+	return s.load().Contains(key)
+}
+
+func (s *RCUSortedMap[K, V]) GetIndexOfGreater(key K) int {
+	return s.load().GetIndexOfGreater(key)
+}
+func (s *RCUSortedMap[K, V]) GetIndexOfGreaterOrEqual(key K) int {
+	return s.load().GetIndexOfGreaterOrEqual(key)
+}
+
+func (s *RCUSortedMap[K, V]) GetGreater(key K) []V {
+	return s.load().GetGreater(key)
+}
+func (s *RCUSortedMap[K, V]) GetGreaterOrEqual(key K) []V {
+	return s.load().GetGreaterOrEqual(key)
+}
+func (s *RCUSortedMap[K, V]) GetLess(key K) []V {
+	return s.load().GetLess(key)
+}
+func (s *RCUSortedMap[K, V]) GetLessOrEqual(key K) []V {
+	return s.load().GetLessOrEqual(key)
+}
+
+func (s *RCUSortedMap[K, V]) GetByInclusiveRange(startKey K, endKey K) []V {
+	return s.load().GetByInclusiveRange(startKey, endKey)
+}
@@ -0,0 +1,66 @@
+package sortedmap_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/sapphi-red/sortedmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRCUSortedMap_InsertDelete(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewRCUSortedMap[int, string](5)
+	assert.Equal(t, 0, m.Insert(1, "1"))
+	assert.Equal(t, -1, m.Insert(1, "1"))
+	assert.Equal(t, 1, m.Insert(3, "3"))
+
+	snapshot := m.Load()
+	assert.Equal(t, 0, m.Delete(1))
+	assert.Equal(t, -1, m.Delete(1))
+
+	assert.Equal(t, []string{"3"}, m.GetGreaterOrEqual(0))
+	assert.Equal(t, []string{"1", "3"}, snapshot.GetGreaterOrEqual(0))
+
+	m.Clear()
+	assert.Equal(t, 0, m.Size())
+	assert.Equal(t, 2, snapshot.Size())
+}
+
+func TestRCUSortedMap_Update(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewRCUSortedMap[int, string](5)
+	m.InsertAll([]int{1, 2}, []string{"1", "2"})
+	m.Update(func(tx *sortedmap.NoLockSortedMap[int, string]) {
+		tx.Delete(1)
+		tx.Insert(5, "5")
+	})
+	assert.Equal(t, []string{"2", "5"}, m.GetByInclusiveRange(0, 10))
+	assert.Equal(t, false, m.Contains(1))
+}
+
+func TestRCUSortedMap_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewRCUSortedMap[int, int](0)
+	var wg sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				values := m.GetGreaterOrEqual(0)
+				for j := 1; j < len(values); j++ {
+					assert.Less(t, values[j-1], values[j])
+				}
+			}
+		}()
+	}
+	for i := 0; i < 200; i++ {
+		m.Insert(i, i)
+	}
+	wg.Wait()
+	assert.Equal(t, 200, m.Size())
+}
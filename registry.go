@@ -0,0 +1,172 @@
+package sortedmap
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var ErrDuplicateName = errors.New("sortedmap: name is already registered")
+
+// StatsSource is implemented by SortedSet, SortedMap and SortedMapCalc.
+// The Registry calls it from the goroutines reading it, concurrently with
+// the owner of the container. The NoLock containers have the same methods,
+// but must not be modified while they are registered.
+type StatsSource interface {
+	Size() int
+	Capacity() int
+	Stats() Stats
+}
+
+var (
+	_ StatsSource = (*SortedSet[int])(nil)
+	_ StatsSource = (*SortedMap[int, int])(nil)
+	_ StatsSource = (*SortedMapCalc[int, int])(nil)
+)
+
+// Registry publishes the statistics of named containers. It implements
+// expvar.Var and serves the Prometheus text format over HTTP.
+type Registry struct {
+	m       sync.Mutex
+	sources map[string]StatsSource
+}
+
+func NewRegistry() *Registry {
+	return &Registry{sources: map[string]StatsSource{}}
+}
+
+func (r *Registry) Register(name string, src StatsSource) error {
+	r.m.Lock()
+	defer r.m.Unlock()
+	if _, ok := r.sources[name]; ok {
+		return ErrDuplicateName
+	}
+	r.sources[name] = src
+	return nil
+}
+
+func (r *Registry) Unregister(name string) {
+	r.m.Lock()
+	delete(r.sources, name)
+	r.m.Unlock()
+}
+
+type registryEntry struct {
+	name     string
+	size     int
+	capacity int
+	stats    Stats
+}
+
+// collect reads the sources outside of the registry lock, in name order.
+func (r *Registry) collect() []registryEntry {
+	r.m.Lock()
+	entries := make([]registryEntry, 0, len(r.sources))
+	sources := make([]StatsSource, 0, len(r.sources))
+	for name, src := range r.sources {
+		entries = append(entries, registryEntry{name: name})
+		sources = append(sources, src)
+	}
+	r.m.Unlock()
+
+	for i, src := range sources {
+		entries[i].size = src.Size()
+		entries[i].capacity = src.Capacity()
+		entries[i].stats = src.Stats()
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].name < entries[j].name
+	})
+	return entries
+}
+
+type expvarEntry struct {
+	Size     int
+	Capacity int
+	Stats
+}
+
+// String returns the statistics as a JSON object keyed by name.
+func (r *Registry) String() string {
+	out := map[string]expvarEntry{}
+	for _, e := range r.collect() {
+		out[e.name] = expvarEntry{Size: e.size, Capacity: e.capacity, Stats: e.stats}
+	}
+	b, err := json.Marshal(out)
+	if err != nil {
+		return "{}"
+	}
+	return string(b)
+}
+
+type promMetric struct {
+	name  string
+	help  string
+	kind  string
+	value func(e registryEntry) float64
+}
+
+var promMetrics = []promMetric{
+	{"sortedmap_size", "Number of entries.", "gauge", func(e registryEntry) float64 { return float64(e.size) }},
+	{"sortedmap_capacity", "Capacity of the backing slices.", "gauge", func(e registryEntry) float64 { return float64(e.capacity) }},
+	{"sortedmap_inserts_total", "Inserted entries.", "counter", func(e registryEntry) float64 { return float64(e.stats.Inserts) }},
+	{"sortedmap_deletes_total", "Deleted entries.", "counter", func(e registryEntry) float64 { return float64(e.stats.Deletes) }},
+	{"sortedmap_hits_total", "Lookups which found the key.", "counter", func(e registryEntry) float64 { return float64(e.stats.Hits) }},
+	{"sortedmap_misses_total", "Lookups which did not find the key.", "counter", func(e registryEntry) float64 { return float64(e.stats.Misses) }},
+	{"sortedmap_duplicates_rejected_total", "Inserts rejected because the key existed.", "counter", func(e registryEntry) float64 { return float64(e.stats.DuplicatesRejected) }},
+	{"sortedmap_capacity_rejects_total", "Inserts rejected because the maximum capacity was reached.", "counter", func(e registryEntry) float64 { return float64(e.stats.CapacityRejects) }},
+	{"sortedmap_filter_rejects_total", "Lookups which the Bloom filter answered as misses.", "counter", func(e registryEntry) float64 { return float64(e.stats.FilterRejects) }},
+	{"sortedmap_resizes_total", "Reallocations of the backing slices.", "counter", func(e registryEntry) float64 { return float64(e.stats.Resizes) }},
+	{"sortedmap_lock_acquisitions_total", "Lock acquisitions of the locked wrappers.", "counter", func(e registryEntry) float64 { return float64(e.stats.LockAcquisitions) }},
+	{"sortedmap_lock_contentions_total", "Lock acquisitions which had to wait.", "counter", func(e registryEntry) float64 { return float64(e.stats.LockContentions) }},
+	{"sortedmap_lock_wait_seconds_total", "Time spent waiting for the lock.", "counter", func(e registryEntry) float64 { return e.stats.LockWaitTime.Seconds() }},
+}
+
+// WritePrometheus writes the statistics in the Prometheus text exposition
+// format, labelled by the registered name.
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	entries := r.collect()
+	bw := bufio.NewWriter(w)
+
+	for _, m := range promMetrics {
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind)
+		for _, e := range entries {
+			fmt.Fprintf(bw, "%s{name=%s} %g\n", m.name, promLabel(e.name), m.value(e))
+		}
+	}
+
+	const hist = "sortedmap_range_size"
+	fmt.Fprintf(bw, "# HELP %s Number of values returned by range queries.\n# TYPE %s histogram\n", hist, hist)
+	for _, e := range entries {
+		label := promLabel(e.name)
+		var cumulative uint64
+		for i, n := range e.stats.RangeSizes[:rangeSizeBuckets-1] {
+			cumulative += n
+			// bucket i holds the sizes below 1<<i
+			fmt.Fprintf(bw, "%s_bucket{name=%s,le=\"%d\"} %d\n", hist, label, (1<<i)-1, cumulative)
+		}
+		cumulative += e.stats.RangeSizes[rangeSizeBuckets-1]
+		fmt.Fprintf(bw, "%s_bucket{name=%s,le=\"+Inf\"} %d\n", hist, label, cumulative)
+		fmt.Fprintf(bw, "%s_sum{name=%s} %d\n", hist, label, e.stats.RangeValues)
+		fmt.Fprintf(bw, "%s_count{name=%s} %d\n", hist, label, cumulative)
+	}
+	return bw.Flush()
+}
+
+var promLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func promLabel(s string) string {
+	return `"` + promLabelEscaper.Replace(s) + `"`
+}
+
+// ServeHTTP serves WritePrometheus.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = r.WritePrometheus(w)
+}
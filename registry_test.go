@@ -0,0 +1,58 @@
+package sortedmap_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sapphi-red/sortedmap"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ expvar.Var = (*sortedmap.Registry)(nil)
+
+func TestRegistry_WritePrometheus(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMap[int, string](4)
+	m.EnableStats()
+	m.Insert(1, "1")
+	m.Insert(2, "2")
+	m.GetGreater(0)
+	set := sortedmap.NewSortedSet[int](2)
+
+	r := sortedmap.NewRegistry()
+	assert.NoError(t, r.Register("users", m))
+	assert.NoError(t, r.Register(`a"b`, set))
+	assert.ErrorIs(t, r.Register("users", set), sortedmap.ErrDuplicateName)
+
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+	res, err := http.Get(srv.URL)
+	assert.NoError(t, err)
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	assert.NoError(t, err)
+
+	text := string(body)
+	assert.Contains(t, text, "# TYPE sortedmap_size gauge\nsortedmap_size{name=\"a\\\"b\"} 0\nsortedmap_size{name=\"users\"} 2\n")
+	assert.Contains(t, text, "sortedmap_capacity{name=\"users\"} 4\n")
+	assert.Contains(t, text, "sortedmap_inserts_total{name=\"users\"} 2\n")
+	assert.Contains(t, text, "sortedmap_range_size_bucket{name=\"users\",le=\"1\"} 0\n")
+	assert.Contains(t, text, "sortedmap_range_size_bucket{name=\"users\",le=\"3\"} 1\n")
+	assert.Contains(t, text, "sortedmap_range_size_sum{name=\"users\"} 2\n")
+	assert.Contains(t, text, "sortedmap_range_size_count{name=\"users\"} 1\n")
+
+	r.Unregister(`a"b`)
+	var out map[string]struct {
+		Size    int
+		Inserts uint64
+	}
+	assert.NoError(t, json.Unmarshal([]byte(r.String()), &out))
+	assert.Len(t, out, 1)
+	assert.Equal(t, 2, out["users"].Size)
+	assert.Equal(t, uint64(2), out["users"].Inserts)
+}
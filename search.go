@@ -0,0 +1,126 @@
+package sortedmap
+
+import (
+	"math/bits"
+	"reflect"
+	"unsafe"
+
+	"golang.org/x/exp/constraints"
+	"golang.org/x/exp/slices"
+)
+
+type SearchStrategy int
+
+const (
+	// SearchBinary halves the searched range at each step.
+	SearchBinary SearchStrategy = iota
+	// SearchInterpolation guesses the position from the key values, which
+	// takes O(log log n) steps on near-uniform keys. It only applies to
+	// integer and float keys in the natural order; other containers keep
+	// using binary search.
+	SearchInterpolation
+	// SearchGalloping probes 1, 2, 4, ... entries past the start of the
+	// searched range before a binary search, so it is fastest when the key is
+	// close to the start. For the *WithAfterHint methods, that is the hint.
+	SearchGalloping
+)
+
+// WithSearchStrategy selects how Contains, GetIndexOf* and the other lookups
+// search the keys.
+func WithSearchStrategy(strategy SearchStrategy) Option {
+	return func(o *options) {
+		o.search = strategy
+	}
+}
+
+// floatOf returns a conversion of K to float64, or nil if K is not a number.
+func floatOf[K constraints.Ordered]() func(K) float64 {
+	var zero K
+	switch reflect.TypeOf(zero).Kind() {
+	case reflect.Int:
+		return func(k K) float64 { return float64(*(*int)(unsafe.Pointer(&k))) }
+	case reflect.Int8:
+		return func(k K) float64 { return float64(*(*int8)(unsafe.Pointer(&k))) }
+	case reflect.Int16:
+		return func(k K) float64 { return float64(*(*int16)(unsafe.Pointer(&k))) }
+	case reflect.Int32:
+		return func(k K) float64 { return float64(*(*int32)(unsafe.Pointer(&k))) }
+	case reflect.Int64:
+		return func(k K) float64 { return float64(*(*int64)(unsafe.Pointer(&k))) }
+	case reflect.Uint:
+		return func(k K) float64 { return float64(*(*uint)(unsafe.Pointer(&k))) }
+	case reflect.Uint8:
+		return func(k K) float64 { return float64(*(*uint8)(unsafe.Pointer(&k))) }
+	case reflect.Uint16:
+		return func(k K) float64 { return float64(*(*uint16)(unsafe.Pointer(&k))) }
+	case reflect.Uint32:
+		return func(k K) float64 { return float64(*(*uint32)(unsafe.Pointer(&k))) }
+	case reflect.Uint64:
+		return func(k K) float64 { return float64(*(*uint64)(unsafe.Pointer(&k))) }
+	case reflect.Uintptr:
+		return func(k K) float64 { return float64(*(*uintptr)(unsafe.Pointer(&k))) }
+	case reflect.Float32:
+		return func(k K) float64 { return float64(*(*float32)(unsafe.Pointer(&k))) }
+	case reflect.Float64:
+		return func(k K) float64 { return *(*float64)(unsafe.Pointer(&k)) }
+	}
+	return nil
+}
+
+// interpolationSearch works like slices.BinarySearch. It falls back to a
+// binary search after log2(n) guesses, so skewed keys cost at most twice as
+// much as a plain binary search.
+func interpolationSearch[K constraints.Ordered](keys []K, key K, toFloat func(K) float64) (int, bool) {
+	lo, hi := 0, len(keys)-1
+	for guesses := bits.Len(uint(len(keys))); lo <= hi && guesses > 0; guesses-- {
+		if key < keys[lo] {
+			return lo, false
+		}
+		if keys[hi] < key {
+			return hi + 1, false
+		}
+
+		low := toFloat(keys[lo])
+		frac := (toFloat(key) - low) / (toFloat(keys[hi]) - low)
+		if !(frac >= 0) {
+			frac = 0 // NaN when all keys in range are equal
+		} else if frac > 1 {
+			frac = 1
+		}
+		mid := lo + int(frac*float64(hi-lo))
+
+		switch {
+		case keys[mid] < key:
+			lo = mid + 1
+		case key < keys[mid]:
+			hi = mid - 1
+		default:
+			return mid, true
+		}
+	}
+	if lo > hi {
+		return lo, false
+	}
+	pos, exists := slices.BinarySearch(keys[lo:hi+1], key)
+	return lo + pos, exists
+}
+
+// gallopingSearch works like slices.BinarySearchFunc, or slices.BinarySearch
+// when cmp is nil.
+func gallopingSearch[K constraints.Ordered](keys []K, key K, cmp func(K, K) int) (int, bool) {
+	lo, hi := 0, 1
+	for hi <= len(keys) && (cmp == nil && keys[hi-1] < key || cmp != nil && cmp(keys[hi-1], key) < 0) {
+		lo = hi
+		hi *= 2
+	}
+	hi = minInt(hi, len(keys))
+
+	var pos int
+	var exists bool
+	if cmp == nil {
+		pos, exists = slices.BinarySearch(keys[lo:hi], key)
+	} else {
+		pos, exists = slices.BinarySearchFunc(keys[lo:hi], key, cmp)
+	}
+	return lo + pos, exists
+}
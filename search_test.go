@@ -0,0 +1,81 @@
+package sortedmap_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/sapphi-red/sortedmap"
+	"github.com/stretchr/testify/assert"
+)
+
+type timestamp int64
+
+func TestWithSearchStrategy(t *testing.T) {
+	t.Parallel()
+
+	rnd := rand.New(rand.NewSource(1))
+	for _, name := range []string{"uniform", "skewed", "small"} {
+		expected := sortedmap.NewNoLockSortedMap[timestamp, int](0)
+		interpolation := sortedmap.NewNoLockSortedMapWithOptions[timestamp, int](sortedmap.WithSearchStrategy(sortedmap.SearchInterpolation))
+		galloping := sortedmap.NewNoLockSortedMapWithOptions[timestamp, int](sortedmap.WithSearchStrategy(sortedmap.SearchGalloping))
+
+		for i := 0; i < 2000; i++ {
+			var key timestamp
+			switch name {
+			case "uniform":
+				key = timestamp(rnd.Int63n(1 << 40))
+			case "skewed":
+				key = timestamp(math.Exp(rnd.Float64() * 40))
+			case "small":
+				key = timestamp(rnd.Intn(8))
+			}
+			pos := expected.Insert(key, i)
+			assert.Equal(t, pos, interpolation.Insert(key, i))
+			assert.Equal(t, pos, galloping.Insert(key, i))
+		}
+
+		keys := []timestamp{-1, 0, 1, 7, 8, math.MaxInt64}
+		for i := 0; i < 200; i++ {
+			keys = append(keys, timestamp(rnd.Int63n(1<<40)), timestamp(math.Exp(rnd.Float64()*40)))
+		}
+		for _, key := range keys {
+			for _, actual := range []*sortedmap.NoLockSortedMap[timestamp, int]{interpolation, galloping} {
+				assert.Equal(t, expected.Contains(key), actual.Contains(key), name)
+				assert.Equal(t, expected.GetIndexOfGreater(key), actual.GetIndexOfGreater(key), name)
+				assert.Equal(t, expected.GetIndexOfGreaterOrEqual(key), actual.GetIndexOfGreaterOrEqual(key), name)
+			}
+		}
+	}
+}
+
+func TestWithSearchStrategy_Hint(t *testing.T) {
+	t.Parallel()
+
+	set := sortedmap.NewNoLockSortedSetWithOptions[float64](
+		sortedmap.WithSearchStrategy(sortedmap.SearchGalloping),
+		sortedmap.WithDescending(),
+	)
+	set.InsertAllOrdered([]float64{9, 7.5, 5, 3, 1})
+
+	assert.Equal(t, 3, set.InsertWithAfterHint(4, 2))
+	assert.Equal(t, -1, set.InsertWithAfterHint(3, 3))
+	assert.Equal(t, 5, set.DeleteWithAfterHint(1, 3))
+	assert.Equal(t, []float64{9, 7.5, 5, 4, 3}, set.GetGreaterOrEqual(10))
+}
+
+func TestWithSearchStrategy_Fallback(t *testing.T) {
+	t.Parallel()
+
+	set := sortedmap.NewSortedSetWithOptions[string](sortedmap.WithSearchStrategy(sortedmap.SearchInterpolation))
+	set.InsertAll([]string{"b", "a", "c"})
+	assert.True(t, set.Contains("b"))
+	assert.Equal(t, []string{"a", "b"}, set.GetLessOrEqual("b"))
+
+	floats := sortedmap.NewNoLockSortedSetWithOptions[float64](sortedmap.WithSearchStrategy(sortedmap.SearchInterpolation))
+	floats.InsertAll([]float64{math.Inf(-1), -1, 0.5, 2, math.Inf(1)})
+	assert.True(t, floats.Contains(0.5))
+	assert.True(t, floats.Contains(math.Inf(1)))
+	assert.False(t, floats.Contains(1))
+	assert.Equal(t, 3, floats.GetIndexOfGreaterOrEqual(1))
+}
@@ -0,0 +1,250 @@
+package sortedmap
+
+import (
+	"sort"
+	"sync"
+
+	"golang.org/x/exp/constraints"
+)
+
+type sortedMapShard[K constraints.Ordered, V any] struct {
+	s NoLockSortedMap[K, V]
+	m sync.RWMutex
+}
+
+// ShardedSortedMap partitions the key space into ranges, each stored in an
+// independently locked NoLockSortedMap. Shards are split when they grow
+// beyond maxShardSize and merged with their neighbor when both become small,
+// except across the split keys given to NewShardedSortedMap.
+type ShardedSortedMap[K constraints.Ordered, V any] struct {
+	// m guards the shard layout. Operations on a single shard hold it for
+	// reading, splitting and merging shards holds it for writing.
+	m      sync.RWMutex
+	shards []*sortedMapShard[K, V]
+	// shards[i] holds keys in [bounds[i-1], bounds[i])
+	bounds []K
+	// fixed[i] is set if bounds[i] is a split key, which is never merged away
+	fixed []bool
+
+	maxShardSize int
+}
+
+const minMaxShardSize = 2
+
+// NewShardedSortedMap creates a map whose shards are split at splitKeys,
+// which must be in ascending order.
+func NewShardedSortedMap[K constraints.Ordered, V any](maxShardSize int, splitKeys ...K) *ShardedSortedMap[K, V] {
+	if maxShardSize < minMaxShardSize {
+		maxShardSize = minMaxShardSize
+	}
+	s := &ShardedSortedMap[K, V]{
+		shards:       make([]*sortedMapShard[K, V], 0, len(splitKeys)+1),
+		bounds:       append([]K(nil), splitKeys...),
+		fixed:        make([]bool, len(splitKeys)),
+		maxShardSize: maxShardSize,
+	}
+	for i := 0; i <= len(splitKeys); i++ {
+		s.shards = append(s.shards, newSortedMapShard[K, V](nil, nil))
+	}
+	for i := range s.fixed {
+		s.fixed[i] = true
+	}
+	return s
+}
+
+func newSortedMapShard[K constraints.Ordered, V any](keys []K, values []V) *sortedMapShard[K, V] {
+	sh := &sortedMapShard[K, V]{}
+	sh.s.keys = append(make([]K, 0, len(keys)), keys...)
+	sh.s.values = append(make([]V, 0, len(values)), values...)
+	return sh
+}
+
+func (s *ShardedSortedMap[K, V]) route(key K) int {
+	return sort.Search(len(s.bounds), func(i int) bool {
+		return key < s.bounds[i]
+	})
+}
+
+func (s *ShardedSortedMap[K, V]) ShardCount() int {
+	s.m.RLock()
+	n := len(s.shards)
+	s.m.RUnlock()
+	return n
+}
+
+func (s *ShardedSortedMap[K, V]) Size() int {
+	s.m.RLock()
+	l := 0
+	for _, sh := range s.shards {
+		sh.m.RLock()
+		l += sh.s.Size()
+		sh.m.RUnlock()
+	}
+	s.m.RUnlock()
+	return l
+}
+
+func (s *ShardedSortedMap[K, V]) Clear() {
+	s.m.Lock()
+	for _, sh := range s.shards {
+		sh.s.Clear()
+	}
+	s.m.Unlock()
+}
+
+func (s *ShardedSortedMap[K, V]) Insert(key K, value V) bool {
+	s.m.RLock()
+	sh := s.shards[s.route(key)]
+	sh.m.Lock()
+	inserted := sh.s.Insert(key, value) >= 0
+	needsSplit := sh.s.Size() > s.maxShardSize
+	sh.m.Unlock()
+	s.m.RUnlock()
+
+	if needsSplit {
+		s.rebalance()
+	}
+	return inserted
+}
+
+func (s *ShardedSortedMap[K, V]) Delete(key K) bool {
+	s.m.RLock()
+	i := s.route(key)
+	sh := s.shards[i]
+	sh.m.Lock()
+	deleted := sh.s.Delete(key) >= 0
+	needsMerge := deleted && sh.s.Size() < s.maxShardSize/4 && (s.mergeable(i-1) || s.mergeable(i))
+	sh.m.Unlock()
+	s.m.RUnlock()
+
+	if needsMerge {
+		s.rebalance()
+	}
+	return deleted
+}
+
+func (s *ShardedSortedMap[K, V]) Contains(key K) bool {
+	s.m.RLock()
+	sh := s.shards[s.route(key)]
+	sh.m.RLock()
+	res := sh.s.Contains(key)
+	sh.m.RUnlock()
+	s.m.RUnlock()
+	return res
+}
+
+// mergeable reports whether the shards on both sides of bounds[i] may be
+// merged.
+func (s *ShardedSortedMap[K, V]) mergeable(i int) bool {
+	return i >= 0 && i < len(s.bounds) && !s.fixed[i]
+}
+
+// rebalance merges adjacent shards which fit together in half of
+// maxShardSize and splits shards larger than maxShardSize.
+func (s *ShardedSortedMap[K, V]) rebalance() {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	half := s.maxShardSize / 2
+	shards := make([]*sortedMapShard[K, V], 0, len(s.shards))
+	bounds := make([]K, 0, len(s.bounds))
+	fixed := make([]bool, 0, len(s.fixed))
+	for i := 0; i < len(s.shards); i++ {
+		sh := s.shards[i]
+		if sh.s.Size() > s.maxShardSize {
+			keys, values := sh.s.keys, sh.s.values
+			for start := 0; start < len(keys); start += half {
+				end := minInt(start+half, len(keys))
+				if start > 0 {
+					bounds = append(bounds, keys[start])
+					fixed = append(fixed, false)
+				}
+				shards = append(shards, newSortedMapShard(keys[start:end], values[start:end]))
+			}
+		} else {
+			for s.mergeable(i) && sh.s.Size()+s.shards[i+1].s.Size() <= half {
+				next := s.shards[i+1]
+				sh.s.keys = append(sh.s.keys, next.s.keys...)
+				sh.s.values = append(sh.s.values, next.s.values...)
+				i++
+			}
+			shards = append(shards, sh)
+		}
+		if i < len(s.bounds) {
+			bounds = append(bounds, s.bounds[i])
+			fixed = append(fixed, s.fixed[i])
+		}
+	}
+	s.shards = shards
+	s.bounds = bounds
+	s.fixed = fixed
+}
+
+// GetByInclusiveRange returns a copy of the values in the range. All shards
+// overlapping the range are read locked together, so the result is a
+// consistent view even when the range spans several shards.
+func (s *ShardedSortedMap[K, V]) GetByInclusiveRange(startKey K, endKey K) []V {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	first := s.route(startKey)
+	last := s.route(endKey)
+	for i := first; i <= last; i++ {
+		s.shards[i].m.RLock()
+	}
+	res := []V{}
+	for i := first; i <= last; i++ {
+		res = append(res, s.shards[i].s.GetByInclusiveRange(startKey, endKey)...)
+	}
+	for i := first; i <= last; i++ {
+		s.shards[i].m.RUnlock()
+	}
+	return res
+}
+
+// Range calls fn for each entry in key order. Entries are copied out of one
+// shard at a time and fn is called without holding any lock, so fn may
+// modify the map.
+func (s *ShardedSortedMap[K, V]) Range(fn func(key K, value V) bool) {
+	var after K
+	started := false
+	for {
+		keys, values := s.nextShardEntries(after, started)
+		if len(keys) == 0 {
+			return
+		}
+		for i := range keys {
+			if !fn(keys[i], values[i]) {
+				return
+			}
+		}
+		after = keys[len(keys)-1]
+		started = true
+	}
+}
+
+func (s *ShardedSortedMap[K, V]) nextShardEntries(after K, started bool) ([]K, []V) {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	i := 0
+	if started {
+		i = s.route(after)
+	}
+	for ; i < len(s.shards); i++ {
+		sh := s.shards[i]
+		sh.m.RLock()
+		pos := 0
+		if started {
+			pos = sh.s.GetIndexOfGreater(after)
+		}
+		if pos < sh.s.Size() {
+			keys := append([]K(nil), sh.s.keys[pos:]...)
+			values := append([]V(nil), sh.s.values[pos:]...)
+			sh.m.RUnlock()
+			return keys, values
+		}
+		sh.m.RUnlock()
+	}
+	return nil, nil
+}
@@ -0,0 +1,120 @@
+package sortedmap_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/sapphi-red/sortedmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardedSortedMap_InsertDelete(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewShardedSortedMap[int, string](4, 10)
+	assert.Equal(t, 2, m.ShardCount())
+	assert.Equal(t, true, m.Insert(1, "1"))
+	assert.Equal(t, false, m.Insert(1, "1"))
+	assert.Equal(t, true, m.Insert(10, "10"))
+	assert.Equal(t, true, m.Contains(10))
+	assert.Equal(t, 2, m.Size())
+
+	assert.Equal(t, true, m.Delete(10))
+	assert.Equal(t, false, m.Delete(10))
+	assert.Equal(t, false, m.Contains(10))
+	assert.Equal(t, 1, m.Size())
+
+	m.Clear()
+	assert.Equal(t, 0, m.Size())
+}
+
+func TestShardedSortedMap_SplitMerge(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewShardedSortedMap[int, int](8)
+	for i := 0; i < 100; i++ {
+		m.Insert(i, i)
+	}
+	assert.Greater(t, m.ShardCount(), 100/8)
+	assert.Equal(t, 100, m.Size())
+
+	assert.Equal(t, []int{18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30}, m.GetByInclusiveRange(18, 30))
+	assert.Equal(t, []int{}, m.GetByInclusiveRange(30, 18))
+
+	for i := 0; i < 95; i++ {
+		m.Delete(i)
+	}
+	assert.Less(t, m.ShardCount(), 4)
+	assert.Equal(t, []int{95, 96, 97, 98, 99}, m.GetByInclusiveRange(0, 100))
+}
+
+func TestShardedSortedMap_SplitKeys(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewShardedSortedMap[int, int](8, 100, 200)
+	for i := 0; i < 20; i++ {
+		m.Insert(i, i)
+	}
+	assert.Greater(t, m.ShardCount(), 3)
+	for i := 0; i < 20; i++ {
+		m.Delete(i)
+	}
+	assert.Equal(t, 3, m.ShardCount())
+
+	m.Insert(150, 150)
+	m.Delete(150)
+	assert.Equal(t, 3, m.ShardCount())
+	assert.Equal(t, []int{}, m.GetByInclusiveRange(0, 300))
+}
+
+func TestShardedSortedMap_Range(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewShardedSortedMap[int, int](4)
+	for i := 20; i > 0; i-- {
+		m.Insert(i, i*10)
+	}
+
+	var keys []int
+	m.Range(func(key int, value int) bool {
+		assert.Equal(t, key*10, value)
+		keys = append(keys, key)
+		return key < 15
+	})
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}, keys)
+
+	count := 0
+	m.Range(func(key int, value int) bool {
+		m.Delete(key)
+		count++
+		return true
+	})
+	assert.Equal(t, 20, count)
+	assert.Equal(t, 0, m.Size())
+}
+
+func TestShardedSortedMap_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewShardedSortedMap[int, int](16)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 250; i++ {
+				m.Insert(i*8+g, g)
+				m.GetByInclusiveRange(i, i+50)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 2000, m.Size())
+	prev := -1
+	m.Range(func(key int, value int) bool {
+		assert.Equal(t, prev+1, key)
+		prev = key
+		return true
+	})
+}
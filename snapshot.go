@@ -0,0 +1,384 @@
+package sortedmap
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+
+	"golang.org/x/exp/constraints"
+)
+
+const snapshotVersion = 1
+
+const (
+	snapshotKindSet byte = iota + 1
+	snapshotKindMap
+	snapshotKindMapCalc
+	snapshotKindPatch
+)
+
+var snapshotMagic = [4]byte{'S', 'R', 'T', 'D'}
+
+var crc32c = crc32.MakeTable(crc32.Castagnoli)
+
+var (
+	ErrSnapshotHeader   = errors.New("sortedmap: invalid snapshot header")
+	ErrSnapshotChecksum = errors.New("sortedmap: snapshot checksum mismatch")
+	ErrSnapshotOrder    = errors.New("sortedmap: snapshot keys are not in strictly ascending order")
+)
+
+type SnapshotError struct {
+	Offset int64
+	Err    error
+}
+
+func (e *SnapshotError) Error() string {
+	return fmt.Sprintf("sortedmap: corrupted snapshot at offset %d: %v", e.Offset, e.Err)
+}
+
+func (e *SnapshotError) Unwrap() error {
+	return e.Err
+}
+
+type snapshotWriter struct {
+	bw   *bufio.Writer
+	hash hash.Hash32
+	n    int64
+}
+
+func newSnapshotWriter(w io.Writer) *snapshotWriter {
+	return &snapshotWriter{
+		bw:   bufio.NewWriter(w),
+		hash: crc32.New(crc32c),
+	}
+}
+
+func (w *snapshotWriter) Write(p []byte) (int, error) {
+	n, err := w.bw.Write(p)
+	w.hash.Write(p[:n])
+	w.n += int64(n)
+	return n, err
+}
+
+func (w *snapshotWriter) writeHeader(kind byte, count int) error {
+	var buf [6 + binary.MaxVarintLen64]byte
+	copy(buf[:], snapshotMagic[:])
+	buf[4] = kind
+	buf[5] = snapshotVersion
+	n := binary.PutUvarint(buf[6:], uint64(count))
+	_, err := w.Write(buf[:6+n])
+	return err
+}
+
+func (w *snapshotWriter) finish() (int64, error) {
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], w.hash.Sum32())
+	n, err := w.bw.Write(sum[:])
+	w.n += int64(n)
+	if err != nil {
+		return w.n, err
+	}
+	return w.n, w.bw.Flush()
+}
+
+// snapshotReader never reads past the end of the snapshot, so that whatever
+// follows it in r stays readable. It reads r byte by byte unless r is an
+// io.ByteReader, so an unbuffered r should be wrapped in a bufio.Reader.
+type snapshotReader struct {
+	r    io.Reader
+	br   io.ByteReader
+	hash hash.Hash32
+	n    int64
+}
+
+func newSnapshotReader(r io.Reader) *snapshotReader {
+	return &snapshotReader{
+		r:    r,
+		br:   asByteReader(r),
+		hash: crc32.New(crc32c),
+	}
+}
+
+func (r *snapshotReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.hash.Write(p[:n])
+	r.n += int64(n)
+	return n, err
+}
+
+func (r *snapshotReader) ReadByte() (byte, error) {
+	b, err := r.br.ReadByte()
+	if err != nil {
+		return b, err
+	}
+	r.hash.Write([]byte{b})
+	r.n++
+	return b, nil
+}
+
+func (r *snapshotReader) fail(err error) error {
+	return &SnapshotError{Offset: r.n, Err: unexpectedEOF(err)}
+}
+
+func (r *snapshotReader) readHeader(kind byte) (int, error) {
+	var buf [6]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return 0, r.fail(err)
+	}
+	if [4]byte{buf[0], buf[1], buf[2], buf[3]} != snapshotMagic || buf[4] != kind || buf[5] != snapshotVersion {
+		return 0, r.fail(ErrSnapshotHeader)
+	}
+	count, err := binary.ReadUvarint(r)
+	if err != nil {
+		return 0, r.fail(err)
+	}
+	if count > uint64(maxInt) {
+		return 0, r.fail(ErrSnapshotHeader)
+	}
+	return int(count), nil
+}
+
+func (r *snapshotReader) finish() (int64, error) {
+	expected := r.hash.Sum32()
+	var sum [4]byte
+	n, err := io.ReadFull(r.r, sum[:])
+	r.n += int64(n)
+	if err != nil {
+		return r.n, r.fail(err)
+	}
+	if binary.BigEndian.Uint32(sum[:]) != expected {
+		return r.n, r.fail(ErrSnapshotChecksum)
+	}
+	return r.n, nil
+}
+
+// restoreCapacity bounds the up-front allocation so that a corrupted count
+// cannot trigger a huge allocation before the checksum is verified.
+func restoreCapacity(count int) int {
+	return minInt(count, 1<<16)
+}
+
+func appendSorted[K constraints.Ordered](keys []K, key K, compare func(K, K) int) ([]K, bool) {
+	if len(keys) > 0 && compare(keys[len(keys)-1], key) >= 0 {
+		return keys, false
+	}
+	return append(keys, key), true
+}
+
+type SetSnapshot[K constraints.Ordered] struct {
+	s     *NoLockSortedSet[K]
+	m     rwLocker
+	codec Codec[K]
+}
+
+func (s *NoLockSortedSet[K]) Snapshot(codec Codec[K]) *SetSnapshot[K] {
+	return &SetSnapshot[K]{s: s, codec: codec}
+}
+
+func (s *SortedSet[K]) Snapshot(codec Codec[K]) *SetSnapshot[K] {
+	return &SetSnapshot[K]{s: &s.s, m: &s.m, codec: codec}
+}
+
+func (p *SetSnapshot[K]) WriteTo(w io.Writer) (int64, error) {
+	if p.m != nil {
+		p.m.RLock()
+		defer p.m.RUnlock()
+	}
+
+	sw := newSnapshotWriter(w)
+	if err := sw.writeHeader(snapshotKindSet, len(p.s.values)); err != nil {
+		return sw.n, err
+	}
+	for i := range p.s.values {
+		if err := p.codec.Encode(sw, p.s.values[i]); err != nil {
+			return sw.n, err
+		}
+	}
+	return sw.finish()
+}
+
+// ReadFrom replaces the set with the snapshot read from r, reading nothing
+// past its end. A snapshot holding more values than WithMaxCapacity allows is
+// rejected with ErrMaxCapacity.
+func (p *SetSnapshot[K]) ReadFrom(r io.Reader) (int64, error) {
+	sr := newSnapshotReader(r)
+	count, err := sr.readHeader(snapshotKindSet)
+	if err != nil {
+		return sr.n, err
+	}
+	if count > p.s.layout.limit(count) {
+		return sr.n, ErrMaxCapacity
+	}
+
+	values := make([]K, 0, restoreCapacity(count))
+	for i := 0; i < count; i++ {
+		value, err := p.codec.Decode(sr)
+		if err != nil {
+			return sr.n, sr.fail(err)
+		}
+		var ok bool
+		if values, ok = appendSorted(values, value, p.s.layout.compare); !ok {
+			return sr.n, sr.fail(ErrSnapshotOrder)
+		}
+	}
+	if _, err := sr.finish(); err != nil {
+		return sr.n, err
+	}
+
+	if p.m != nil {
+		p.m.Lock()
+		defer p.m.Unlock()
+	}
+	p.s.replaceAll(values)
+	return sr.n, nil
+}
+
+type MapSnapshot[K constraints.Ordered, V any] struct {
+	s          *NoLockSortedMap[K, V]
+	m          rwLocker
+	keyCodec   Codec[K]
+	valueCodec Codec[V]
+}
+
+func (s *NoLockSortedMap[K, V]) Snapshot(keyCodec Codec[K], valueCodec Codec[V]) *MapSnapshot[K, V] {
+	return &MapSnapshot[K, V]{s: s, keyCodec: keyCodec, valueCodec: valueCodec}
+}
+
+func (s *SortedMap[K, V]) Snapshot(keyCodec Codec[K], valueCodec Codec[V]) *MapSnapshot[K, V] {
+	return &MapSnapshot[K, V]{s: &s.s, m: &s.m, keyCodec: keyCodec, valueCodec: valueCodec}
+}
+
+func (p *MapSnapshot[K, V]) WriteTo(w io.Writer) (int64, error) {
+	if p.m != nil {
+		p.m.RLock()
+		defer p.m.RUnlock()
+	}
+
+	sw := newSnapshotWriter(w)
+	if err := sw.writeHeader(snapshotKindMap, len(p.s.keys)); err != nil {
+		return sw.n, err
+	}
+	for i := range p.s.keys {
+		if err := p.keyCodec.Encode(sw, p.s.keys[i]); err != nil {
+			return sw.n, err
+		}
+		if err := p.valueCodec.Encode(sw, p.s.values[i]); err != nil {
+			return sw.n, err
+		}
+	}
+	return sw.finish()
+}
+
+// ReadFrom replaces the map with the snapshot read from r, like
+// SetSnapshot.ReadFrom.
+func (p *MapSnapshot[K, V]) ReadFrom(r io.Reader) (int64, error) {
+	sr := newSnapshotReader(r)
+	count, err := sr.readHeader(snapshotKindMap)
+	if err != nil {
+		return sr.n, err
+	}
+	if count > p.s.layout.limit(count) {
+		return sr.n, ErrMaxCapacity
+	}
+
+	keys := make([]K, 0, restoreCapacity(count))
+	values := make([]V, 0, restoreCapacity(count))
+	for i := 0; i < count; i++ {
+		key, err := p.keyCodec.Decode(sr)
+		if err != nil {
+			return sr.n, sr.fail(err)
+		}
+		var ok bool
+		if keys, ok = appendSorted(keys, key, p.s.layout.compare); !ok {
+			return sr.n, sr.fail(ErrSnapshotOrder)
+		}
+		value, err := p.valueCodec.Decode(sr)
+		if err != nil {
+			return sr.n, sr.fail(err)
+		}
+		values = append(values, value)
+	}
+	if _, err := sr.finish(); err != nil {
+		return sr.n, err
+	}
+
+	if p.m != nil {
+		p.m.Lock()
+		defer p.m.Unlock()
+	}
+	p.s.replaceAll(keys, values)
+	return sr.n, nil
+}
+
+type MapCalcSnapshot[K constraints.Ordered, V any] struct {
+	s     *NoLockSortedMapCalc[K, V]
+	m     rwLocker
+	codec Codec[V]
+}
+
+func (s *NoLockSortedMapCalc[K, V]) Snapshot(codec Codec[V]) *MapCalcSnapshot[K, V] {
+	return &MapCalcSnapshot[K, V]{s: s, codec: codec}
+}
+
+func (s *SortedMapCalc[K, V]) Snapshot(codec Codec[V]) *MapCalcSnapshot[K, V] {
+	return &MapCalcSnapshot[K, V]{s: &s.s, m: &s.m, codec: codec}
+}
+
+func (p *MapCalcSnapshot[K, V]) WriteTo(w io.Writer) (int64, error) {
+	if p.m != nil {
+		p.m.RLock()
+		defer p.m.RUnlock()
+	}
+
+	sw := newSnapshotWriter(w)
+	if err := sw.writeHeader(snapshotKindMapCalc, len(p.s.values)); err != nil {
+		return sw.n, err
+	}
+	for i := range p.s.values {
+		if err := p.codec.Encode(sw, p.s.values[i]); err != nil {
+			return sw.n, err
+		}
+	}
+	return sw.finish()
+}
+
+// ReadFrom replaces the map with the snapshot read from r, like
+// SetSnapshot.ReadFrom.
+func (p *MapCalcSnapshot[K, V]) ReadFrom(r io.Reader) (int64, error) {
+	sr := newSnapshotReader(r)
+	count, err := sr.readHeader(snapshotKindMapCalc)
+	if err != nil {
+		return sr.n, err
+	}
+	if count > p.s.layout.limit(count) {
+		return sr.n, ErrMaxCapacity
+	}
+
+	keys := make([]K, 0, restoreCapacity(count))
+	values := make([]V, 0, restoreCapacity(count))
+	for i := 0; i < count; i++ {
+		value, err := p.codec.Decode(sr)
+		if err != nil {
+			return sr.n, sr.fail(err)
+		}
+		var ok bool
+		if keys, ok = appendSorted(keys, p.s.calcKey(value), p.s.layout.compare); !ok {
+			return sr.n, sr.fail(ErrSnapshotOrder)
+		}
+		values = append(values, value)
+	}
+	if _, err := sr.finish(); err != nil {
+		return sr.n, err
+	}
+
+	if p.m != nil {
+		p.m.Lock()
+		defer p.m.Unlock()
+	}
+	p.s.replaceAll(keys, values)
+	return sr.n, nil
+}
@@ -0,0 +1,165 @@
+package sortedmap_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/sapphi-red/sortedmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetSnapshot_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	set := sortedmap.NewNoLockSortedSet[int](5)
+	set.InsertAll([]int{5, -3, 1})
+
+	var buf bytes.Buffer
+	written, err := set.Snapshot(sortedmap.OrderedCodec[int]()).WriteTo(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), written)
+
+	restored := sortedmap.NewSortedSet[int](0)
+	restored.Insert(100)
+	read, err := restored.Snapshot(sortedmap.OrderedCodec[int]()).ReadFrom(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, written, read)
+	assert.Equal(t, []int{-3, 1, 5}, restored.GetGreaterOrEqual(-10))
+}
+
+func TestMapSnapshot_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMap[string, float64](5)
+	m.Insert("b", 2.5)
+	m.Insert("a", -1)
+	m.Insert("", 0)
+
+	var buf bytes.Buffer
+	_, err := m.Snapshot(sortedmap.OrderedCodec[string](), sortedmap.OrderedCodec[float64]()).WriteTo(&buf)
+	assert.NoError(t, err)
+
+	restored := sortedmap.NewNoLockSortedMap[string, float64](0)
+	_, err = restored.Snapshot(sortedmap.OrderedCodec[string](), sortedmap.OrderedCodec[float64]()).ReadFrom(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, restored.Size())
+	assert.Equal(t, []float64{0, -1, 2.5}, restored.GetGreaterOrEqual(""))
+}
+
+func TestMapCalcSnapshot_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewNoLockSortedMapCalc(5, safeAtoi)
+	m.InsertAll([]string{"3", "1", "2"})
+
+	var buf bytes.Buffer
+	_, err := m.Snapshot(sortedmap.OrderedCodec[string]()).WriteTo(&buf)
+	assert.NoError(t, err)
+
+	restored := sortedmap.NewSortedMapCalc(0, safeAtoi)
+	_, err = restored.Snapshot(sortedmap.OrderedCodec[string]()).ReadFrom(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1", "2", "3"}, restored.GetGreaterOrEqual(0))
+	assert.Equal(t, true, restored.Contains(2))
+}
+
+func TestMapSnapshot_Corrupted(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewNoLockSortedMap[int, string](5)
+	m.Insert(1, "1")
+	m.Insert(2, "2")
+	snapshot := m.Snapshot(sortedmap.OrderedCodec[int](), sortedmap.OrderedCodec[string]())
+
+	var buf bytes.Buffer
+	_, err := snapshot.WriteTo(&buf)
+	assert.NoError(t, err)
+	data := buf.Bytes()
+
+	flipped := append([]byte(nil), data...)
+	flipped[len(flipped)-1] ^= 0xff
+	restored := sortedmap.NewNoLockSortedMap[int, string](0)
+	restored.Insert(9, "9")
+	_, err = restored.Snapshot(sortedmap.OrderedCodec[int](), sortedmap.OrderedCodec[string]()).ReadFrom(bytes.NewReader(flipped))
+	var snapshotErr *sortedmap.SnapshotError
+	assert.True(t, errors.As(err, &snapshotErr))
+	assert.ErrorIs(t, err, sortedmap.ErrSnapshotChecksum)
+	assert.Equal(t, []string{"9"}, restored.GetGreaterOrEqual(0))
+
+	_, err = restored.Snapshot(sortedmap.OrderedCodec[int](), sortedmap.OrderedCodec[string]()).ReadFrom(bytes.NewReader(data[:len(data)-3]))
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	_, err = sortedmap.NewNoLockSortedSet[int](0).Snapshot(sortedmap.OrderedCodec[int]()).ReadFrom(bytes.NewReader(data))
+	assert.ErrorIs(t, err, sortedmap.ErrSnapshotHeader)
+}
+
+func TestMapSnapshot_OutOfOrder(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewNoLockSortedMap[int, string](5)
+	m.Insert(1, "1")
+	m.Insert(2, "2")
+
+	var buf bytes.Buffer
+	_, err := m.Snapshot(sortedmap.OrderedCodec[int](), sortedmap.OrderedCodec[string]()).WriteTo(&buf)
+	assert.NoError(t, err)
+
+	negate := sortedmap.CodecFuncs[int]{
+		DecodeFunc: func(r io.Reader) (int, error) {
+			v, err := sortedmap.OrderedCodec[int]().Decode(r)
+			return -v, err
+		},
+	}
+	restored := sortedmap.NewNoLockSortedMap[int, string](0)
+	_, err = restored.Snapshot(negate, sortedmap.OrderedCodec[string]()).ReadFrom(&buf)
+	assert.ErrorIs(t, err, sortedmap.ErrSnapshotOrder)
+	assert.Equal(t, 0, restored.Size())
+}
+
+func TestSetSnapshot_Consecutive(t *testing.T) {
+	t.Parallel()
+
+	a := sortedmap.NewNoLockSortedSet[int](5)
+	a.InsertAll([]int{1, 2, 3})
+	b := sortedmap.NewNoLockSortedSet[int](5)
+	b.InsertAll([]int{4, 5})
+
+	var buf bytes.Buffer
+	_, err := a.Snapshot(sortedmap.OrderedCodec[int]()).WriteTo(&buf)
+	assert.NoError(t, err)
+	_, err = b.Snapshot(sortedmap.OrderedCodec[int]()).WriteTo(&buf)
+	assert.NoError(t, err)
+	buf.WriteString("tail")
+
+	// neither a byte reader nor a plain reader is read past a snapshot
+	for _, r := range []io.Reader{bytes.NewReader(buf.Bytes()), struct{ io.Reader }{bytes.NewReader(buf.Bytes())}} {
+		restored := sortedmap.NewNoLockSortedSet[int](0)
+		_, err = restored.Snapshot(sortedmap.OrderedCodec[int]()).ReadFrom(r)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 3}, restored.GetGreaterOrEqual(0))
+		_, err = restored.Snapshot(sortedmap.OrderedCodec[int]()).ReadFrom(r)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{4, 5}, restored.GetGreaterOrEqual(0))
+		rest, err := io.ReadAll(r)
+		assert.NoError(t, err)
+		assert.Equal(t, "tail", string(rest))
+	}
+}
+
+func TestMapSnapshot_WithMaxCapacity(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewNoLockSortedMap[int, string](5)
+	m.InsertAll([]int{1, 2, 3}, []string{"1", "2", "3"})
+	var buf bytes.Buffer
+	_, err := m.Snapshot(sortedmap.OrderedCodec[int](), sortedmap.OrderedCodec[string]()).WriteTo(&buf)
+	assert.NoError(t, err)
+
+	restored := sortedmap.NewNoLockSortedMapWithOptions[int, string](sortedmap.WithMaxCapacity(2))
+	restored.Insert(0, "0")
+	_, err = restored.Snapshot(sortedmap.OrderedCodec[int](), sortedmap.OrderedCodec[string]()).ReadFrom(&buf)
+	assert.ErrorIs(t, err, sortedmap.ErrMaxCapacity)
+	assert.Equal(t, []string{"0"}, restored.GetGreaterOrEqual(0))
+}
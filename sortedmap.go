@@ -1,14 +1,12 @@
 package sortedmap
 
 import (
-	"sync"
-
 	"golang.org/x/exp/constraints"
 )
 
 type SortedMap[K constraints.Ordered, V any] struct {
 	s NoLockSortedMap[K, V]
-	m sync.RWMutex
+	m observedLock[K, V]
 }
 
 func NewSortedMap[K constraints.Ordered, V any](capacity int) *SortedMap[K, V] {
@@ -20,6 +18,12 @@ func NewSortedMap[K constraints.Ordered, V any](capacity int) *SortedMap[K, V] {
 	}
 }
 
+func NewSortedMapWithOptions[K constraints.Ordered, V any](opts ...Option) *SortedMap[K, V] {
+	return &SortedMap[K, V]{
+		s: *NewNoLockSortedMapWithOptions[K, V](opts...),
+	}
+}
+
 func (s *SortedMap[K, V]) Size() int {
 	s.m.RLock()
 	l := s.s.Size()
@@ -53,6 +57,13 @@ func (s *SortedMap[K, V]) Insert(key K, value V) int {
 	return res
 }
 
+func (s *SortedMap[K, V]) InsertChecked(key K, value V) (int, error) {
+	s.m.Lock()
+	res, err := s.s.InsertChecked(key, value)
+	s.m.Unlock()
+	return res, err
+}
+
 // TODO
 // func (s *SortedMap[K, V]) InsertWithBeforeHint(value K, beforeIndex int) int {
 // 	return 0 // inserted index
@@ -102,6 +113,13 @@ func (s *SortedMap[K, V]) InsertAllOrdered(keys []K, values []V) {
 	s.m.Unlock()
 }
 
+func (s *SortedMap[K, V]) InsertAllChecked(keys []K, values []V) error {
+	s.m.Lock()
+	err := s.s.InsertAllChecked(keys, values)
+	s.m.Unlock()
+	return err
+}
+
 func (s *SortedMap[K, V]) DeleteAll(keys []K) {
 	s.m.Lock()
 	s.s.DeleteAll(keys)
@@ -1,14 +1,12 @@
 package sortedmap
 
 import (
-	"sync"
-
 	"golang.org/x/exp/constraints"
 )
 
 type SortedMapCalc[K constraints.Ordered, V any] struct {
 	s NoLockSortedMapCalc[K, V]
-	m sync.RWMutex
+	m observedLock[K, V]
 }
 
 func NewSortedMapCalc[K constraints.Ordered, V any](capacity int, calcKey func(V) K) *SortedMapCalc[K, V] {
@@ -21,6 +19,12 @@ func NewSortedMapCalc[K constraints.Ordered, V any](capacity int, calcKey func(V
 	}
 }
 
+func NewSortedMapCalcWithOptions[K constraints.Ordered, V any](calcKey func(V) K, opts ...Option) *SortedMapCalc[K, V] {
+	return &SortedMapCalc[K, V]{
+		s: *NewNoLockSortedMapCalcWithOptions(calcKey, opts...),
+	}
+}
+
 func (s *SortedMapCalc[K, V]) Size() int {
 	s.m.RLock()
 	l := s.s.Size()
@@ -54,6 +58,13 @@ func (s *SortedMapCalc[K, V]) Insert(value V) int {
 	return res
 }
 
+func (s *SortedMapCalc[K, V]) InsertChecked(value V) (int, error) {
+	s.m.Lock()
+	res, err := s.s.InsertChecked(value)
+	s.m.Unlock()
+	return res, err
+}
+
 // TODO
 // func (s *SortedMapCalc[K, V]) InsertWithBeforeHint(value K, beforeIndex int) int {
 // 	return 0 // inserted index
@@ -97,6 +108,13 @@ func (s *SortedMapCalc[K, V]) InsertAllOrdered(values []V) {
 	s.m.Unlock()
 }
 
+func (s *SortedMapCalc[K, V]) InsertAllChecked(values []V) error {
+	s.m.Lock()
+	err := s.s.InsertAllChecked(values)
+	s.m.Unlock()
+	return err
+}
+
 func (s *SortedMapCalc[K, V]) DeleteAll(values []V) {
 	s.m.Lock()
 	s.s.DeleteAll(values)
@@ -0,0 +1,327 @@
+package sortedmap
+
+import "context"
+
+func (s *SortedMapCalc[K, V]) TrySize() (int, bool) {
+	if !s.m.TryRLock() {
+		return 0, false
+	}
+	res := s.s.Size()
+	s.m.RUnlock()
+	return res, true
+}
+
+func (s *SortedMapCalc[K, V]) SizeContext(ctx context.Context) (int, error) {
+	if err := rlockContext(ctx, &s.m); err != nil {
+		return 0, err
+	}
+	res := s.s.Size()
+	s.m.RUnlock()
+	return res, nil
+}
+
+func (s *SortedMapCalc[K, V]) TryClear() bool {
+	if !s.m.TryLock() {
+		return false
+	}
+	s.s.Clear()
+	s.m.Unlock()
+	return true
+}
+
+func (s *SortedMapCalc[K, V]) ClearContext(ctx context.Context) error {
+	if err := lockContext(ctx, &s.m); err != nil {
+		return err
+	}
+	s.s.Clear()
+	s.m.Unlock()
+	return nil
+}
+
+func (s *SortedMapCalc[K, V]) TryInsert(value V) (int, bool) {
+	if !s.m.TryLock() {
+		return 0, false
+	}
+	res := s.s.Insert(value)
+	s.m.Unlock()
+	return res, true
+}
+
+func (s *SortedMapCalc[K, V]) InsertContext(ctx context.Context, value V) (int, error) {
+	if err := lockContext(ctx, &s.m); err != nil {
+		return 0, err
+	}
+	res := s.s.Insert(value)
+	s.m.Unlock()
+	return res, nil
+}
+
+func (s *SortedMapCalc[K, V]) TryInsertWithAfterHint(value V, afterIndex int) (int, bool) {
+	if !s.m.TryLock() {
+		return 0, false
+	}
+	res := s.s.InsertWithAfterHint(value, afterIndex)
+	s.m.Unlock()
+	return res, true
+}
+
+func (s *SortedMapCalc[K, V]) InsertWithAfterHintContext(ctx context.Context, value V, afterIndex int) (int, error) {
+	if err := lockContext(ctx, &s.m); err != nil {
+		return 0, err
+	}
+	res := s.s.InsertWithAfterHint(value, afterIndex)
+	s.m.Unlock()
+	return res, nil
+}
+
+func (s *SortedMapCalc[K, V]) TryDelete(value V) (int, bool) {
+	if !s.m.TryLock() {
+		return 0, false
+	}
+	res := s.s.Delete(value)
+	s.m.Unlock()
+	return res, true
+}
+
+func (s *SortedMapCalc[K, V]) DeleteContext(ctx context.Context, value V) (int, error) {
+	if err := lockContext(ctx, &s.m); err != nil {
+		return 0, err
+	}
+	res := s.s.Delete(value)
+	s.m.Unlock()
+	return res, nil
+}
+
+func (s *SortedMapCalc[K, V]) TryDeleteWithAfterHint(value V, afterIndex int) (int, bool) {
+	if !s.m.TryLock() {
+		return 0, false
+	}
+	res := s.s.DeleteWithAfterHint(value, afterIndex)
+	s.m.Unlock()
+	return res, true
+}
+
+func (s *SortedMapCalc[K, V]) DeleteWithAfterHintContext(ctx context.Context, value V, afterIndex int) (int, error) {
+	if err := lockContext(ctx, &s.m); err != nil {
+		return 0, err
+	}
+	res := s.s.DeleteWithAfterHint(value, afterIndex)
+	s.m.Unlock()
+	return res, nil
+}
+
+func (s *SortedMapCalc[K, V]) TryInsertAll(values []V) bool {
+	if !s.m.TryLock() {
+		return false
+	}
+	s.s.InsertAll(values)
+	s.m.Unlock()
+	return true
+}
+
+func (s *SortedMapCalc[K, V]) InsertAllContext(ctx context.Context, values []V) error {
+	if err := lockContext(ctx, &s.m); err != nil {
+		return err
+	}
+	s.s.InsertAll(values)
+	s.m.Unlock()
+	return nil
+}
+
+func (s *SortedMapCalc[K, V]) TryInsertAllOrdered(values []V) bool {
+	if !s.m.TryLock() {
+		return false
+	}
+	s.s.InsertAllOrdered(values)
+	s.m.Unlock()
+	return true
+}
+
+func (s *SortedMapCalc[K, V]) InsertAllOrderedContext(ctx context.Context, values []V) error {
+	if err := lockContext(ctx, &s.m); err != nil {
+		return err
+	}
+	s.s.InsertAllOrdered(values)
+	s.m.Unlock()
+	return nil
+}
+
+func (s *SortedMapCalc[K, V]) TryDeleteAll(values []V) bool {
+	if !s.m.TryLock() {
+		return false
+	}
+	s.s.DeleteAll(values)
+	s.m.Unlock()
+	return true
+}
+
+func (s *SortedMapCalc[K, V]) DeleteAllContext(ctx context.Context, values []V) error {
+	if err := lockContext(ctx, &s.m); err != nil {
+		return err
+	}
+	s.s.DeleteAll(values)
+	s.m.Unlock()
+	return nil
+}
+
+func (s *SortedMapCalc[K, V]) TryDeleteAllOrdered(values []V) bool {
+	if !s.m.TryLock() {
+		return false
+	}
+	s.s.DeleteAllOrdered(values)
+	s.m.Unlock()
+	return true
+}
+
+func (s *SortedMapCalc[K, V]) DeleteAllOrderedContext(ctx context.Context, values []V) error {
+	if err := lockContext(ctx, &s.m); err != nil {
+		return err
+	}
+	s.s.DeleteAllOrdered(values)
+	s.m.Unlock()
+	return nil
+}
+
+func (s *SortedMapCalc[K, V]) TryContains(key K) (bool, bool) {
+	if !s.m.TryRLock() {
+		return false, false
+	}
+	res := s.s.Contains(key)
+	s.m.RUnlock()
+	return res, true
+}
+
+func (s *SortedMapCalc[K, V]) ContainsContext(ctx context.Context, key K) (bool, error) {
+	if err := rlockContext(ctx, &s.m); err != nil {
+		return false, err
+	}
+	res := s.s.Contains(key)
+	s.m.RUnlock()
+	return res, nil
+}
+
+func (s *SortedMapCalc[K, V]) TryGetIndexOfGreater(key K) (int, bool) {
+	if !s.m.TryRLock() {
+		return 0, false
+	}
+	res := s.s.GetIndexOfGreater(key)
+	s.m.RUnlock()
+	return res, true
+}
+
+func (s *SortedMapCalc[K, V]) GetIndexOfGreaterContext(ctx context.Context, key K) (int, error) {
+	if err := rlockContext(ctx, &s.m); err != nil {
+		return 0, err
+	}
+	res := s.s.GetIndexOfGreater(key)
+	s.m.RUnlock()
+	return res, nil
+}
+
+func (s *SortedMapCalc[K, V]) TryGetIndexOfGreaterOrEqual(key K) (int, bool) {
+	if !s.m.TryRLock() {
+		return 0, false
+	}
+	res := s.s.GetIndexOfGreaterOrEqual(key)
+	s.m.RUnlock()
+	return res, true
+}
+
+func (s *SortedMapCalc[K, V]) GetIndexOfGreaterOrEqualContext(ctx context.Context, key K) (int, error) {
+	if err := rlockContext(ctx, &s.m); err != nil {
+		return 0, err
+	}
+	res := s.s.GetIndexOfGreaterOrEqual(key)
+	s.m.RUnlock()
+	return res, nil
+}
+
+func (s *SortedMapCalc[K, V]) TryGetGreater(key K) ([]V, bool) {
+	if !s.m.TryRLock() {
+		return nil, false
+	}
+	res := s.s.GetGreater(key)
+	s.m.RUnlock()
+	return res, true
+}
+
+func (s *SortedMapCalc[K, V]) GetGreaterContext(ctx context.Context, key K) ([]V, error) {
+	if err := rlockContext(ctx, &s.m); err != nil {
+		return nil, err
+	}
+	res := s.s.GetGreater(key)
+	s.m.RUnlock()
+	return res, nil
+}
+
+func (s *SortedMapCalc[K, V]) TryGetGreaterOrEqual(key K) ([]V, bool) {
+	if !s.m.TryRLock() {
+		return nil, false
+	}
+	res := s.s.GetGreaterOrEqual(key)
+	s.m.RUnlock()
+	return res, true
+}
+
+func (s *SortedMapCalc[K, V]) GetGreaterOrEqualContext(ctx context.Context, key K) ([]V, error) {
+	if err := rlockContext(ctx, &s.m); err != nil {
+		return nil, err
+	}
+	res := s.s.GetGreaterOrEqual(key)
+	s.m.RUnlock()
+	return res, nil
+}
+
+func (s *SortedMapCalc[K, V]) TryGetLess(key K) ([]V, bool) {
+	if !s.m.TryRLock() {
+		return nil, false
+	}
+	res := s.s.GetLess(key)
+	s.m.RUnlock()
+	return res, true
+}
+
+func (s *SortedMapCalc[K, V]) GetLessContext(ctx context.Context, key K) ([]V, error) {
+	if err := rlockContext(ctx, &s.m); err != nil {
+		return nil, err
+	}
+	res := s.s.GetLess(key)
+	s.m.RUnlock()
+	return res, nil
+}
+
+func (s *SortedMapCalc[K, V]) TryGetLessOrEqual(key K) ([]V, bool) {
+	if !s.m.TryRLock() {
+		return nil, false
+	}
+	res := s.s.GetLessOrEqual(key)
+	s.m.RUnlock()
+	return res, true
+}
+
+func (s *SortedMapCalc[K, V]) GetLessOrEqualContext(ctx context.Context, key K) ([]V, error) {
+	if err := rlockContext(ctx, &s.m); err != nil {
+		return nil, err
+	}
+	res := s.s.GetLessOrEqual(key)
+	s.m.RUnlock()
+	return res, nil
+}
+
+func (s *SortedMapCalc[K, V]) TryGetByInclusiveRange(startKey K, endKey K) ([]V, bool) {
+	if !s.m.TryRLock() {
+		return nil, false
+	}
+	res := s.s.GetByInclusiveRange(startKey, endKey)
+	s.m.RUnlock()
+	return res, true
+}
+
+func (s *SortedMapCalc[K, V]) GetByInclusiveRangeContext(ctx context.Context, startKey K, endKey K) ([]V, error) {
+	if err := rlockContext(ctx, &s.m); err != nil {
+		return nil, err
+	}
+	res := s.s.GetByInclusiveRange(startKey, endKey)
+	s.m.RUnlock()
+	return res, nil
+}
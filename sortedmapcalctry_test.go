@@ -0,0 +1,34 @@
+package sortedmap_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sapphi-red/sortedmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortedMapCalc_Try(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMapCalc(5, safeAtoi)
+	assert.NoError(t, m.InsertAllContext(context.Background(), []string{"3", "1"}))
+
+	release := holdLock(m.Update)
+	_, ok := m.TryInsert("2")
+	assert.False(t, ok)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := m.GetLessContext(ctx, 3)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	release()
+
+	pos, ok := m.TryInsert("2")
+	assert.True(t, ok)
+	assert.Equal(t, 1, pos)
+	res, err := m.GetLessContext(context.Background(), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1", "2"}, res)
+}
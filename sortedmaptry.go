@@ -0,0 +1,345 @@
+package sortedmap
+
+import "context"
+
+func (s *SortedMap[K, V]) TrySize() (int, bool) {
+	if !s.m.TryRLock() {
+		return 0, false
+	}
+	res := s.s.Size()
+	s.m.RUnlock()
+	return res, true
+}
+
+func (s *SortedMap[K, V]) SizeContext(ctx context.Context) (int, error) {
+	if err := rlockContext(ctx, &s.m); err != nil {
+		return 0, err
+	}
+	res := s.s.Size()
+	s.m.RUnlock()
+	return res, nil
+}
+
+func (s *SortedMap[K, V]) TryClear() bool {
+	if !s.m.TryLock() {
+		return false
+	}
+	s.s.Clear()
+	s.m.Unlock()
+	return true
+}
+
+func (s *SortedMap[K, V]) ClearContext(ctx context.Context) error {
+	if err := lockContext(ctx, &s.m); err != nil {
+		return err
+	}
+	s.s.Clear()
+	s.m.Unlock()
+	return nil
+}
+
+func (s *SortedMap[K, V]) TryInsert(key K, value V) (int, bool) {
+	if !s.m.TryLock() {
+		return 0, false
+	}
+	res := s.s.Insert(key, value)
+	s.m.Unlock()
+	return res, true
+}
+
+func (s *SortedMap[K, V]) InsertContext(ctx context.Context, key K, value V) (int, error) {
+	if err := lockContext(ctx, &s.m); err != nil {
+		return 0, err
+	}
+	res := s.s.Insert(key, value)
+	s.m.Unlock()
+	return res, nil
+}
+
+func (s *SortedMap[K, V]) TryInsertWithAfterHint(key K, value V, afterIndex int) (int, bool) {
+	if !s.m.TryLock() {
+		return 0, false
+	}
+	res := s.s.InsertWithAfterHint(key, value, afterIndex)
+	s.m.Unlock()
+	return res, true
+}
+
+func (s *SortedMap[K, V]) InsertWithAfterHintContext(ctx context.Context, key K, value V, afterIndex int) (int, error) {
+	if err := lockContext(ctx, &s.m); err != nil {
+		return 0, err
+	}
+	res := s.s.InsertWithAfterHint(key, value, afterIndex)
+	s.m.Unlock()
+	return res, nil
+}
+
+func (s *SortedMap[K, V]) TryDelete(key K) (int, bool) {
+	if !s.m.TryLock() {
+		return 0, false
+	}
+	res := s.s.Delete(key)
+	s.m.Unlock()
+	return res, true
+}
+
+func (s *SortedMap[K, V]) DeleteContext(ctx context.Context, key K) (int, error) {
+	if err := lockContext(ctx, &s.m); err != nil {
+		return 0, err
+	}
+	res := s.s.Delete(key)
+	s.m.Unlock()
+	return res, nil
+}
+
+func (s *SortedMap[K, V]) TryDeleteWithAfterHint(value K, afterIndex int) (int, bool) {
+	if !s.m.TryLock() {
+		return 0, false
+	}
+	res := s.s.DeleteWithAfterHint(value, afterIndex)
+	s.m.Unlock()
+	return res, true
+}
+
+func (s *SortedMap[K, V]) DeleteWithAfterHintContext(ctx context.Context, value K, afterIndex int) (int, error) {
+	if err := lockContext(ctx, &s.m); err != nil {
+		return 0, err
+	}
+	res := s.s.DeleteWithAfterHint(value, afterIndex)
+	s.m.Unlock()
+	return res, nil
+}
+
+func (s *SortedMap[K, V]) TryInsertAll(keys []K, values []V) bool {
+	if !s.m.TryLock() {
+		return false
+	}
+	s.s.InsertAll(keys, values)
+	s.m.Unlock()
+	return true
+}
+
+func (s *SortedMap[K, V]) InsertAllContext(ctx context.Context, keys []K, values []V) error {
+	if err := lockContext(ctx, &s.m); err != nil {
+		return err
+	}
+	s.s.InsertAll(keys, values)
+	s.m.Unlock()
+	return nil
+}
+
+func (s *SortedMap[K, V]) TryInsertAllByMap(m map[K]V) bool {
+	if !s.m.TryLock() {
+		return false
+	}
+	s.s.InsertAllByMap(m)
+	s.m.Unlock()
+	return true
+}
+
+func (s *SortedMap[K, V]) InsertAllByMapContext(ctx context.Context, m map[K]V) error {
+	if err := lockContext(ctx, &s.m); err != nil {
+		return err
+	}
+	s.s.InsertAllByMap(m)
+	s.m.Unlock()
+	return nil
+}
+
+func (s *SortedMap[K, V]) TryInsertAllOrdered(keys []K, values []V) bool {
+	if !s.m.TryLock() {
+		return false
+	}
+	s.s.InsertAllOrdered(keys, values)
+	s.m.Unlock()
+	return true
+}
+
+func (s *SortedMap[K, V]) InsertAllOrderedContext(ctx context.Context, keys []K, values []V) error {
+	if err := lockContext(ctx, &s.m); err != nil {
+		return err
+	}
+	s.s.InsertAllOrdered(keys, values)
+	s.m.Unlock()
+	return nil
+}
+
+func (s *SortedMap[K, V]) TryDeleteAll(keys []K) bool {
+	if !s.m.TryLock() {
+		return false
+	}
+	s.s.DeleteAll(keys)
+	s.m.Unlock()
+	return true
+}
+
+func (s *SortedMap[K, V]) DeleteAllContext(ctx context.Context, keys []K) error {
+	if err := lockContext(ctx, &s.m); err != nil {
+		return err
+	}
+	s.s.DeleteAll(keys)
+	s.m.Unlock()
+	return nil
+}
+
+func (s *SortedMap[K, V]) TryDeleteAllOrdered(keys []K) bool {
+	if !s.m.TryLock() {
+		return false
+	}
+	s.s.DeleteAllOrdered(keys)
+	s.m.Unlock()
+	return true
+}
+
+func (s *SortedMap[K, V]) DeleteAllOrderedContext(ctx context.Context, keys []K) error {
+	if err := lockContext(ctx, &s.m); err != nil {
+		return err
+	}
+	s.s.DeleteAllOrdered(keys)
+	s.m.Unlock()
+	return nil
+}
+
+func (s *SortedMap[K, V]) TryContains(key K) (bool, bool) {
+	if !s.m.TryRLock() {
+		return false, false
+	}
+	res := s.s.Contains(key)
+	s.m.RUnlock()
+	return res, true
+}
+
+func (s *SortedMap[K, V]) ContainsContext(ctx context.Context, key K) (bool, error) {
+	if err := rlockContext(ctx, &s.m); err != nil {
+		return false, err
+	}
+	res := s.s.Contains(key)
+	s.m.RUnlock()
+	return res, nil
+}
+
+func (s *SortedMap[K, V]) TryGetIndexOfGreater(key K) (int, bool) {
+	if !s.m.TryRLock() {
+		return 0, false
+	}
+	res := s.s.GetIndexOfGreater(key)
+	s.m.RUnlock()
+	return res, true
+}
+
+func (s *SortedMap[K, V]) GetIndexOfGreaterContext(ctx context.Context, key K) (int, error) {
+	if err := rlockContext(ctx, &s.m); err != nil {
+		return 0, err
+	}
+	res := s.s.GetIndexOfGreater(key)
+	s.m.RUnlock()
+	return res, nil
+}
+
+func (s *SortedMap[K, V]) TryGetIndexOfGreaterOrEqual(key K) (int, bool) {
+	if !s.m.TryRLock() {
+		return 0, false
+	}
+	res := s.s.GetIndexOfGreaterOrEqual(key)
+	s.m.RUnlock()
+	return res, true
+}
+
+func (s *SortedMap[K, V]) GetIndexOfGreaterOrEqualContext(ctx context.Context, key K) (int, error) {
+	if err := rlockContext(ctx, &s.m); err != nil {
+		return 0, err
+	}
+	res := s.s.GetIndexOfGreaterOrEqual(key)
+	s.m.RUnlock()
+	return res, nil
+}
+
+func (s *SortedMap[K, V]) TryGetGreater(key K) ([]V, bool) {
+	if !s.m.TryRLock() {
+		return nil, false
+	}
+	res := s.s.GetGreater(key)
+	s.m.RUnlock()
+	return res, true
+}
+
+func (s *SortedMap[K, V]) GetGreaterContext(ctx context.Context, key K) ([]V, error) {
+	if err := rlockContext(ctx, &s.m); err != nil {
+		return nil, err
+	}
+	res := s.s.GetGreater(key)
+	s.m.RUnlock()
+	return res, nil
+}
+
+func (s *SortedMap[K, V]) TryGetGreaterOrEqual(key K) ([]V, bool) {
+	if !s.m.TryRLock() {
+		return nil, false
+	}
+	res := s.s.GetGreaterOrEqual(key)
+	s.m.RUnlock()
+	return res, true
+}
+
+func (s *SortedMap[K, V]) GetGreaterOrEqualContext(ctx context.Context, key K) ([]V, error) {
+	if err := rlockContext(ctx, &s.m); err != nil {
+		return nil, err
+	}
+	res := s.s.GetGreaterOrEqual(key)
+	s.m.RUnlock()
+	return res, nil
+}
+
+func (s *SortedMap[K, V]) TryGetLess(key K) ([]V, bool) {
+	if !s.m.TryRLock() {
+		return nil, false
+	}
+	res := s.s.GetLess(key)
+	s.m.RUnlock()
+	return res, true
+}
+
+func (s *SortedMap[K, V]) GetLessContext(ctx context.Context, key K) ([]V, error) {
+	if err := rlockContext(ctx, &s.m); err != nil {
+		return nil, err
+	}
+	res := s.s.GetLess(key)
+	s.m.RUnlock()
+	return res, nil
+}
+
+func (s *SortedMap[K, V]) TryGetLessOrEqual(key K) ([]V, bool) {
+	if !s.m.TryRLock() {
+		return nil, false
+	}
+	res := s.s.GetLessOrEqual(key)
+	s.m.RUnlock()
+	return res, true
+}
+
+func (s *SortedMap[K, V]) GetLessOrEqualContext(ctx context.Context, key K) ([]V, error) {
+	if err := rlockContext(ctx, &s.m); err != nil {
+		return nil, err
+	}
+	res := s.s.GetLessOrEqual(key)
+	s.m.RUnlock()
+	return res, nil
+}
+
+func (s *SortedMap[K, V]) TryGetByInclusiveRange(startKey K, endKey K) ([]V, bool) {
+	if !s.m.TryRLock() {
+		return nil, false
+	}
+	res := s.s.GetByInclusiveRange(startKey, endKey)
+	s.m.RUnlock()
+	return res, true
+}
+
+func (s *SortedMap[K, V]) GetByInclusiveRangeContext(ctx context.Context, startKey K, endKey K) ([]V, error) {
+	if err := rlockContext(ctx, &s.m); err != nil {
+		return nil, err
+	}
+	res := s.s.GetByInclusiveRange(startKey, endKey)
+	s.m.RUnlock()
+	return res, nil
+}
@@ -0,0 +1,78 @@
+package sortedmap_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sapphi-red/sortedmap"
+	"github.com/stretchr/testify/assert"
+)
+
+// holdLock keeps the write lock of a locked wrapper through update until the
+// returned function is called.
+func holdLock[T any](update func(func(T) error) error) func() {
+	locked := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		_ = update(func(T) error {
+			close(locked)
+			<-release
+			return nil
+		})
+		close(done)
+	}()
+	<-locked
+	return func() {
+		close(release)
+		<-done
+	}
+}
+
+func TestSortedMap_Try(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMap[int, string](5)
+	pos, ok := m.TryInsert(2, "2")
+	assert.True(t, ok)
+	assert.Equal(t, 0, pos)
+
+	release := holdLock(m.Update)
+	_, ok = m.TryInsert(1, "1")
+	assert.False(t, ok)
+	_, ok = m.TryContains(2)
+	assert.False(t, ok)
+	assert.False(t, m.TryClear())
+	release()
+
+	res, ok := m.TryGetByInclusiveRange(0, 5)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"2"}, res)
+}
+
+func TestSortedMap_Context(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMap[int, string](5)
+	release := holdLock(m.Update)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := m.InsertContext(ctx, 1, "1")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	_, err = m.SizeContext(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		release()
+	}()
+	pos, err := m.InsertContext(context.Background(), 1, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, pos)
+	assert.NoError(t, m.InsertAllByMapContext(context.Background(), map[int]string{2: "2"}))
+	contains, err := m.ContainsContext(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.True(t, contains)
+}
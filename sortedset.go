@@ -1,14 +1,12 @@
 package sortedmap
 
 import (
-	"sync"
-
 	"golang.org/x/exp/constraints"
 )
 
 type SortedSet[K constraints.Ordered] struct {
 	s NoLockSortedSet[K]
-	m sync.RWMutex
+	m observedLock[K, struct{}]
 }
 
 func NewSortedSet[K constraints.Ordered](capacity int) *SortedSet[K] {
@@ -19,6 +17,12 @@ func NewSortedSet[K constraints.Ordered](capacity int) *SortedSet[K] {
 	}
 }
 
+func NewSortedSetWithOptions[K constraints.Ordered](opts ...Option) *SortedSet[K] {
+	return &SortedSet[K]{
+		s: *NewNoLockSortedSetWithOptions[K](opts...),
+	}
+}
+
 func (s *SortedSet[K]) Size() int {
 	s.m.RLock()
 	l := s.s.Size()
@@ -52,6 +56,13 @@ func (s *SortedSet[K]) Insert(value K) int {
 	return res
 }
 
+func (s *SortedSet[K]) InsertChecked(value K) (int, error) {
+	s.m.Lock()
+	res, err := s.s.InsertChecked(value)
+	s.m.Unlock()
+	return res, err
+}
+
 // TODO
 // func (s *SortedSet[K]) InsertWithBeforeHint(value K, beforeIndex int) int {
 // 	return 0 // inserted index
@@ -95,6 +106,13 @@ func (s *SortedSet[K]) InsertAllOrdered(values []K) {
 	s.m.Unlock()
 }
 
+func (s *SortedSet[K]) InsertAllChecked(values []K) error {
+	s.m.Lock()
+	err := s.s.InsertAllChecked(values)
+	s.m.Unlock()
+	return err
+}
+
 func (s *SortedSet[K]) DeleteAll(values []K) {
 	s.m.Lock()
 	s.s.DeleteAll(values)
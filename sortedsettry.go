@@ -0,0 +1,327 @@
+package sortedmap
+
+import "context"
+
+func (s *SortedSet[K]) TrySize() (int, bool) {
+	if !s.m.TryRLock() {
+		return 0, false
+	}
+	res := s.s.Size()
+	s.m.RUnlock()
+	return res, true
+}
+
+func (s *SortedSet[K]) SizeContext(ctx context.Context) (int, error) {
+	if err := rlockContext(ctx, &s.m); err != nil {
+		return 0, err
+	}
+	res := s.s.Size()
+	s.m.RUnlock()
+	return res, nil
+}
+
+func (s *SortedSet[K]) TryClear() bool {
+	if !s.m.TryLock() {
+		return false
+	}
+	s.s.Clear()
+	s.m.Unlock()
+	return true
+}
+
+func (s *SortedSet[K]) ClearContext(ctx context.Context) error {
+	if err := lockContext(ctx, &s.m); err != nil {
+		return err
+	}
+	s.s.Clear()
+	s.m.Unlock()
+	return nil
+}
+
+func (s *SortedSet[K]) TryInsert(value K) (int, bool) {
+	if !s.m.TryLock() {
+		return 0, false
+	}
+	res := s.s.Insert(value)
+	s.m.Unlock()
+	return res, true
+}
+
+func (s *SortedSet[K]) InsertContext(ctx context.Context, value K) (int, error) {
+	if err := lockContext(ctx, &s.m); err != nil {
+		return 0, err
+	}
+	res := s.s.Insert(value)
+	s.m.Unlock()
+	return res, nil
+}
+
+func (s *SortedSet[K]) TryInsertWithAfterHint(value K, afterIndex int) (int, bool) {
+	if !s.m.TryLock() {
+		return 0, false
+	}
+	res := s.s.InsertWithAfterHint(value, afterIndex)
+	s.m.Unlock()
+	return res, true
+}
+
+func (s *SortedSet[K]) InsertWithAfterHintContext(ctx context.Context, value K, afterIndex int) (int, error) {
+	if err := lockContext(ctx, &s.m); err != nil {
+		return 0, err
+	}
+	res := s.s.InsertWithAfterHint(value, afterIndex)
+	s.m.Unlock()
+	return res, nil
+}
+
+func (s *SortedSet[K]) TryDelete(value K) (int, bool) {
+	if !s.m.TryLock() {
+		return 0, false
+	}
+	res := s.s.Delete(value)
+	s.m.Unlock()
+	return res, true
+}
+
+func (s *SortedSet[K]) DeleteContext(ctx context.Context, value K) (int, error) {
+	if err := lockContext(ctx, &s.m); err != nil {
+		return 0, err
+	}
+	res := s.s.Delete(value)
+	s.m.Unlock()
+	return res, nil
+}
+
+func (s *SortedSet[K]) TryDeleteWithAfterHint(value K, afterIndex int) (int, bool) {
+	if !s.m.TryLock() {
+		return 0, false
+	}
+	res := s.s.DeleteWithAfterHint(value, afterIndex)
+	s.m.Unlock()
+	return res, true
+}
+
+func (s *SortedSet[K]) DeleteWithAfterHintContext(ctx context.Context, value K, afterIndex int) (int, error) {
+	if err := lockContext(ctx, &s.m); err != nil {
+		return 0, err
+	}
+	res := s.s.DeleteWithAfterHint(value, afterIndex)
+	s.m.Unlock()
+	return res, nil
+}
+
+func (s *SortedSet[K]) TryInsertAll(values []K) bool {
+	if !s.m.TryLock() {
+		return false
+	}
+	s.s.InsertAll(values)
+	s.m.Unlock()
+	return true
+}
+
+func (s *SortedSet[K]) InsertAllContext(ctx context.Context, values []K) error {
+	if err := lockContext(ctx, &s.m); err != nil {
+		return err
+	}
+	s.s.InsertAll(values)
+	s.m.Unlock()
+	return nil
+}
+
+func (s *SortedSet[K]) TryInsertAllOrdered(values []K) bool {
+	if !s.m.TryLock() {
+		return false
+	}
+	s.s.InsertAllOrdered(values)
+	s.m.Unlock()
+	return true
+}
+
+func (s *SortedSet[K]) InsertAllOrderedContext(ctx context.Context, values []K) error {
+	if err := lockContext(ctx, &s.m); err != nil {
+		return err
+	}
+	s.s.InsertAllOrdered(values)
+	s.m.Unlock()
+	return nil
+}
+
+func (s *SortedSet[K]) TryDeleteAll(values []K) bool {
+	if !s.m.TryLock() {
+		return false
+	}
+	s.s.DeleteAll(values)
+	s.m.Unlock()
+	return true
+}
+
+func (s *SortedSet[K]) DeleteAllContext(ctx context.Context, values []K) error {
+	if err := lockContext(ctx, &s.m); err != nil {
+		return err
+	}
+	s.s.DeleteAll(values)
+	s.m.Unlock()
+	return nil
+}
+
+func (s *SortedSet[K]) TryDeleteAllOrdered(values []K) bool {
+	if !s.m.TryLock() {
+		return false
+	}
+	s.s.DeleteAllOrdered(values)
+	s.m.Unlock()
+	return true
+}
+
+func (s *SortedSet[K]) DeleteAllOrderedContext(ctx context.Context, values []K) error {
+	if err := lockContext(ctx, &s.m); err != nil {
+		return err
+	}
+	s.s.DeleteAllOrdered(values)
+	s.m.Unlock()
+	return nil
+}
+
+func (s *SortedSet[K]) TryContains(value K) (bool, bool) {
+	if !s.m.TryRLock() {
+		return false, false
+	}
+	res := s.s.Contains(value)
+	s.m.RUnlock()
+	return res, true
+}
+
+func (s *SortedSet[K]) ContainsContext(ctx context.Context, value K) (bool, error) {
+	if err := rlockContext(ctx, &s.m); err != nil {
+		return false, err
+	}
+	res := s.s.Contains(value)
+	s.m.RUnlock()
+	return res, nil
+}
+
+func (s *SortedSet[K]) TryGetIndexOfGreater(value K) (int, bool) {
+	if !s.m.TryRLock() {
+		return 0, false
+	}
+	res := s.s.GetIndexOfGreater(value)
+	s.m.RUnlock()
+	return res, true
+}
+
+func (s *SortedSet[K]) GetIndexOfGreaterContext(ctx context.Context, value K) (int, error) {
+	if err := rlockContext(ctx, &s.m); err != nil {
+		return 0, err
+	}
+	res := s.s.GetIndexOfGreater(value)
+	s.m.RUnlock()
+	return res, nil
+}
+
+func (s *SortedSet[K]) TryGetIndexOfGreaterOrEqual(value K) (int, bool) {
+	if !s.m.TryRLock() {
+		return 0, false
+	}
+	res := s.s.GetIndexOfGreaterOrEqual(value)
+	s.m.RUnlock()
+	return res, true
+}
+
+func (s *SortedSet[K]) GetIndexOfGreaterOrEqualContext(ctx context.Context, value K) (int, error) {
+	if err := rlockContext(ctx, &s.m); err != nil {
+		return 0, err
+	}
+	res := s.s.GetIndexOfGreaterOrEqual(value)
+	s.m.RUnlock()
+	return res, nil
+}
+
+func (s *SortedSet[K]) TryGetGreater(value K) ([]K, bool) {
+	if !s.m.TryRLock() {
+		return nil, false
+	}
+	res := s.s.GetGreater(value)
+	s.m.RUnlock()
+	return res, true
+}
+
+func (s *SortedSet[K]) GetGreaterContext(ctx context.Context, value K) ([]K, error) {
+	if err := rlockContext(ctx, &s.m); err != nil {
+		return nil, err
+	}
+	res := s.s.GetGreater(value)
+	s.m.RUnlock()
+	return res, nil
+}
+
+func (s *SortedSet[K]) TryGetGreaterOrEqual(value K) ([]K, bool) {
+	if !s.m.TryRLock() {
+		return nil, false
+	}
+	res := s.s.GetGreaterOrEqual(value)
+	s.m.RUnlock()
+	return res, true
+}
+
+func (s *SortedSet[K]) GetGreaterOrEqualContext(ctx context.Context, value K) ([]K, error) {
+	if err := rlockContext(ctx, &s.m); err != nil {
+		return nil, err
+	}
+	res := s.s.GetGreaterOrEqual(value)
+	s.m.RUnlock()
+	return res, nil
+}
+
+func (s *SortedSet[K]) TryGetLess(value K) ([]K, bool) {
+	if !s.m.TryRLock() {
+		return nil, false
+	}
+	res := s.s.GetLess(value)
+	s.m.RUnlock()
+	return res, true
+}
+
+func (s *SortedSet[K]) GetLessContext(ctx context.Context, value K) ([]K, error) {
+	if err := rlockContext(ctx, &s.m); err != nil {
+		return nil, err
+	}
+	res := s.s.GetLess(value)
+	s.m.RUnlock()
+	return res, nil
+}
+
+func (s *SortedSet[K]) TryGetLessOrEqual(value K) ([]K, bool) {
+	if !s.m.TryRLock() {
+		return nil, false
+	}
+	res := s.s.GetLessOrEqual(value)
+	s.m.RUnlock()
+	return res, true
+}
+
+func (s *SortedSet[K]) GetLessOrEqualContext(ctx context.Context, value K) ([]K, error) {
+	if err := rlockContext(ctx, &s.m); err != nil {
+		return nil, err
+	}
+	res := s.s.GetLessOrEqual(value)
+	s.m.RUnlock()
+	return res, nil
+}
+
+func (s *SortedSet[K]) TryGetByInclusiveRange(startValue K, endValue K) ([]K, bool) {
+	if !s.m.TryRLock() {
+		return nil, false
+	}
+	res := s.s.GetByInclusiveRange(startValue, endValue)
+	s.m.RUnlock()
+	return res, true
+}
+
+func (s *SortedSet[K]) GetByInclusiveRangeContext(ctx context.Context, startValue K, endValue K) ([]K, error) {
+	if err := rlockContext(ctx, &s.m); err != nil {
+		return nil, err
+	}
+	res := s.s.GetByInclusiveRange(startValue, endValue)
+	s.m.RUnlock()
+	return res, nil
+}
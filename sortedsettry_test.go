@@ -0,0 +1,35 @@
+package sortedmap_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sapphi-red/sortedmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortedSet_Try(t *testing.T) {
+	t.Parallel()
+
+	set := sortedmap.NewSortedSet[int](5)
+	assert.True(t, set.TryInsertAll([]int{3, 1, 2}))
+
+	release := holdLock(set.Update)
+	_, ok := set.TryDelete(1)
+	assert.False(t, ok)
+	_, ok = set.TryGetGreater(1)
+	assert.False(t, ok)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := set.DeleteContext(ctx, 1)
+	assert.ErrorIs(t, err, context.Canceled)
+	release()
+
+	pos, err := set.DeleteContext(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, pos)
+	res, ok := set.TryGetGreater(1)
+	assert.True(t, ok)
+	assert.Equal(t, []int{2, 3}, res)
+}
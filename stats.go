@@ -0,0 +1,291 @@
+package sortedmap
+
+import (
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+const rangeSizeBuckets = 16
+
+// Stats is a snapshot of the counters collected after EnableStats.
+type Stats struct {
+	Inserts            uint64
+	Deletes            uint64
+	Hits               uint64
+	Misses             uint64
+	DuplicatesRejected uint64
+	// CapacityRejects counts the inserts rejected because the container held
+	// WithMaxCapacity entries.
+	CapacityRejects uint64
+	// FilterRejects counts the misses of Contains answered by the Bloom
+	// filter alone. They are also counted in Misses.
+	FilterRejects uint64
+	// Resizes counts the reallocations of the backing slices, either by
+	// ExtendCapacityTo or by growing on insert.
+	Resizes uint64
+	// RangeSizes[i] counts the range queries which returned n values where
+	// bits.Len(n) == i, that is 0, 1, 2-3, 4-7 and so on. The last bucket
+	// also counts everything larger.
+	RangeSizes [rangeSizeBuckets]uint64
+	// RangeValues is the total number of values returned by range queries.
+	RangeValues uint64
+
+	// The lock counters are only collected by the locked wrappers.
+	LockAcquisitions uint64
+	LockContentions  uint64
+	LockWaitTime     time.Duration
+}
+
+// statsCounters is shared by a container and its lock. All methods are no-ops
+// on nil so that the disabled case costs a single comparison.
+type statsCounters struct {
+	inserts            uint64
+	deletes            uint64
+	hits               uint64
+	misses             uint64
+	duplicatesRejected uint64
+	filterRejects      uint64
+	capacityRejects    uint64
+	resizes            uint64
+	rangeSizes         [rangeSizeBuckets]uint64
+	rangeValues        uint64
+	lockAcquisitions   uint64
+	lockContentions    uint64
+	lockWaitNanos      uint64
+}
+
+func (c *statsCounters) inserted(oldCap int, newCap int) {
+	if c == nil {
+		return
+	}
+	atomic.AddUint64(&c.inserts, 1)
+	if oldCap != newCap {
+		atomic.AddUint64(&c.resizes, 1)
+	}
+}
+
+func (c *statsCounters) insertedN(n int) {
+	if c == nil {
+		return
+	}
+	atomic.AddUint64(&c.inserts, uint64(n))
+}
+
+func (c *statsCounters) deleted(n int) {
+	if c == nil {
+		return
+	}
+	atomic.AddUint64(&c.deletes, uint64(n))
+}
+
+func (c *statsCounters) lookup(hit bool) {
+	if c == nil {
+		return
+	}
+	if hit {
+		atomic.AddUint64(&c.hits, 1)
+	} else {
+		atomic.AddUint64(&c.misses, 1)
+	}
+}
+
+func (c *statsCounters) duplicate() {
+	if c == nil {
+		return
+	}
+	atomic.AddUint64(&c.duplicatesRejected, 1)
+}
+
+func (c *statsCounters) capacityRejected() {
+	if c == nil {
+		return
+	}
+	atomic.AddUint64(&c.capacityRejects, 1)
+}
+
+func (c *statsCounters) filtered() {
+	if c == nil {
+		return
+	}
+	atomic.AddUint64(&c.misses, 1)
+	atomic.AddUint64(&c.filterRejects, 1)
+}
+
+func (c *statsCounters) resized() {
+	if c == nil {
+		return
+	}
+	atomic.AddUint64(&c.resizes, 1)
+}
+
+func (c *statsCounters) rangeSize(n int) {
+	if c == nil {
+		return
+	}
+	bucket := minInt(bits.Len(uint(n)), rangeSizeBuckets-1)
+	atomic.AddUint64(&c.rangeSizes[bucket], 1)
+	atomic.AddUint64(&c.rangeValues, uint64(n))
+}
+
+func (c *statsCounters) lockAcquired(contended bool, wait time.Duration) {
+	if c == nil {
+		return
+	}
+	atomic.AddUint64(&c.lockAcquisitions, 1)
+	if contended {
+		atomic.AddUint64(&c.lockContentions, 1)
+		atomic.AddUint64(&c.lockWaitNanos, uint64(wait))
+	}
+}
+
+func (c *statsCounters) snapshot() Stats {
+	if c == nil {
+		return Stats{}
+	}
+	st := Stats{
+		Inserts:            atomic.LoadUint64(&c.inserts),
+		Deletes:            atomic.LoadUint64(&c.deletes),
+		Hits:               atomic.LoadUint64(&c.hits),
+		Misses:             atomic.LoadUint64(&c.misses),
+		DuplicatesRejected: atomic.LoadUint64(&c.duplicatesRejected),
+		FilterRejects:      atomic.LoadUint64(&c.filterRejects),
+		CapacityRejects:    atomic.LoadUint64(&c.capacityRejects),
+		Resizes:            atomic.LoadUint64(&c.resizes),
+		RangeValues:        atomic.LoadUint64(&c.rangeValues),
+		LockAcquisitions:   atomic.LoadUint64(&c.lockAcquisitions),
+		LockContentions:    atomic.LoadUint64(&c.lockContentions),
+		LockWaitTime:       time.Duration(atomic.LoadUint64(&c.lockWaitNanos)),
+	}
+	for i := range c.rangeSizes {
+		st.RangeSizes[i] = atomic.LoadUint64(&c.rangeSizes[i])
+	}
+	return st
+}
+
+// add adds the counters of st, which Update collects separately so that a
+// rolled back transaction leaves no trace.
+func (c *statsCounters) add(st Stats) {
+	if c == nil {
+		return
+	}
+	atomic.AddUint64(&c.inserts, st.Inserts)
+	atomic.AddUint64(&c.deletes, st.Deletes)
+	atomic.AddUint64(&c.hits, st.Hits)
+	atomic.AddUint64(&c.misses, st.Misses)
+	atomic.AddUint64(&c.duplicatesRejected, st.DuplicatesRejected)
+	atomic.AddUint64(&c.filterRejects, st.FilterRejects)
+	atomic.AddUint64(&c.capacityRejects, st.CapacityRejects)
+	atomic.AddUint64(&c.resizes, st.Resizes)
+	for i := range st.RangeSizes {
+		atomic.AddUint64(&c.rangeSizes[i], st.RangeSizes[i])
+	}
+	atomic.AddUint64(&c.rangeValues, st.RangeValues)
+}
+
+func (l *observedLock[K, V]) loadStats() *statsCounters {
+	c, _ := l.stats.Load().(*statsCounters)
+	return c
+}
+
+func (l *observedLock[K, V]) Lock() {
+	stats := l.loadStats()
+	if stats == nil {
+		l.RWMutex.Lock()
+		return
+	}
+	if l.RWMutex.TryLock() {
+		stats.lockAcquired(false, 0)
+		return
+	}
+	start := time.Now()
+	l.RWMutex.Lock()
+	stats.lockAcquired(true, time.Since(start))
+}
+
+func (l *observedLock[K, V]) RLock() {
+	stats := l.loadStats()
+	if stats == nil {
+		l.RWMutex.RLock()
+		return
+	}
+	if l.RWMutex.TryRLock() {
+		stats.lockAcquired(false, 0)
+		return
+	}
+	start := time.Now()
+	l.RWMutex.RLock()
+	stats.lockAcquired(true, time.Since(start))
+}
+
+// EnableStats starts collecting Stats. It is safe to call more than once.
+func (s *NoLockSortedSet[K]) EnableStats() {
+	if s.stats == nil {
+		s.stats = &statsCounters{}
+	}
+}
+
+func (s *NoLockSortedSet[K]) Stats() Stats {
+	return s.stats.snapshot()
+}
+
+// EnableStats starts collecting Stats. It is safe to call more than once.
+func (s *NoLockSortedMap[K, V]) EnableStats() {
+	if s.stats == nil {
+		s.stats = &statsCounters{}
+	}
+}
+
+func (s *NoLockSortedMap[K, V]) Stats() Stats {
+	return s.stats.snapshot()
+}
+
+// EnableStats starts collecting Stats. It is safe to call more than once.
+func (s *NoLockSortedMapCalc[K, V]) EnableStats() {
+	if s.stats == nil {
+		s.stats = &statsCounters{}
+	}
+}
+
+func (s *NoLockSortedMapCalc[K, V]) Stats() Stats {
+	return s.stats.snapshot()
+}
+
+// EnableStats starts collecting Stats, including the time spent waiting for
+// the lock. It is safe to call more than once.
+func (s *SortedSet[K]) EnableStats() {
+	s.m.Lock()
+	s.s.EnableStats()
+	s.m.stats.Store(s.s.stats)
+	s.m.Unlock()
+}
+
+func (s *SortedSet[K]) Stats() Stats {
+	return s.m.loadStats().snapshot()
+}
+
+// EnableStats starts collecting Stats, including the time spent waiting for
+// the lock. It is safe to call more than once.
+func (s *SortedMap[K, V]) EnableStats() {
+	s.m.Lock()
+	s.s.EnableStats()
+	s.m.stats.Store(s.s.stats)
+	s.m.Unlock()
+}
+
+func (s *SortedMap[K, V]) Stats() Stats {
+	return s.m.loadStats().snapshot()
+}
+
+// EnableStats starts collecting Stats, including the time spent waiting for
+// the lock. It is safe to call more than once.
+func (s *SortedMapCalc[K, V]) EnableStats() {
+	s.m.Lock()
+	s.s.EnableStats()
+	s.m.stats.Store(s.s.stats)
+	s.m.Unlock()
+}
+
+func (s *SortedMapCalc[K, V]) Stats() Stats {
+	return s.m.loadStats().snapshot()
+}
@@ -0,0 +1,109 @@
+package sortedmap_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sapphi-red/sortedmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoLockSortedMap_Stats(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewNoLockSortedMap[int, string](2)
+	m.Insert(0, "0")
+	assert.Equal(t, sortedmap.Stats{}, m.Stats())
+
+	m.EnableStats()
+	m.Insert(1, "1")
+	m.Insert(2, "2")
+	m.Insert(1, "1")
+	m.Contains(1)
+	m.Contains(5)
+	m.Delete(0)
+	m.GetByInclusiveRange(1, 2)
+	m.GetGreater(5)
+
+	st := m.Stats()
+	assert.Equal(t, uint64(2), st.Inserts)
+	assert.Equal(t, uint64(1), st.Deletes)
+	assert.Equal(t, uint64(1), st.Hits)
+	assert.Equal(t, uint64(1), st.Misses)
+	assert.Equal(t, uint64(1), st.DuplicatesRejected)
+	assert.Equal(t, uint64(1), st.Resizes)
+	assert.Equal(t, uint64(1), st.RangeSizes[0])
+	assert.Equal(t, uint64(1), st.RangeSizes[2])
+}
+
+func TestNoLockSortedMap_StatsWithGrowthFactor(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewNoLockSortedMapWithOptions[int, string](
+		sortedmap.WithCapacity(1),
+		sortedmap.WithGrowthFactor(2),
+	)
+	m.EnableStats()
+	for i := 0; i < 10; i++ {
+		m.Insert(i, "")
+	}
+	assert.Equal(t, 16, m.Capacity())
+	assert.Equal(t, uint64(4), m.Stats().Resizes)
+
+	set := sortedmap.NewNoLockSortedSetWithOptions[int](
+		sortedmap.WithCapacity(1),
+		sortedmap.WithGrowthFactor(2),
+	)
+	set.EnableStats()
+	for i := 0; i < 3; i++ {
+		set.Insert(i)
+	}
+	assert.Equal(t, uint64(2), set.Stats().Resizes)
+
+	calc := sortedmap.NewNoLockSortedMapCalcWithOptions(safeAtoi,
+		sortedmap.WithCapacity(1),
+		sortedmap.WithGrowthFactor(2),
+	)
+	calc.EnableStats()
+	for _, v := range []string{"1", "2", "3"} {
+		calc.Insert(v)
+	}
+	assert.Equal(t, uint64(2), calc.Stats().Resizes)
+}
+
+func TestSortedSet_Stats(t *testing.T) {
+	t.Parallel()
+
+	set := sortedmap.NewSortedSet[int](5)
+	set.EnableStats()
+	set.InsertAll([]int{1, 2, 3, 2})
+	set.Clear()
+
+	st := set.Stats()
+	assert.Equal(t, uint64(3), st.Inserts)
+	assert.Equal(t, uint64(3), st.Deletes)
+	assert.Equal(t, uint64(1), st.DuplicatesRejected)
+	assert.Equal(t, uint64(0), st.Resizes)
+	assert.Equal(t, uint64(2), st.LockAcquisitions)
+}
+
+func TestSortedMapCalc_Stats(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMapCalc(5, safeAtoi)
+	m.EnableStats()
+
+	release := holdLock(m.Update)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		release()
+	}()
+	m.Insert("1")
+	m.GetLessOrEqual(1)
+
+	st := m.Stats()
+	assert.Equal(t, uint64(1), st.Inserts)
+	assert.Equal(t, uint64(1), st.RangeSizes[1])
+	assert.Equal(t, uint64(1), st.LockContentions)
+	assert.GreaterOrEqual(t, st.LockWaitTime, 5*time.Millisecond)
+}
@@ -0,0 +1,54 @@
+package sortedmap
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	minLockBackoff = 10 * time.Microsecond
+	maxLockBackoff = 5 * time.Millisecond
+)
+
+type tryLocker interface {
+	TryLock() bool
+	TryRLock() bool
+}
+
+func lockContext(ctx context.Context, l tryLocker) error {
+	return acquireContext(ctx, l.TryLock)
+}
+
+func rlockContext(ctx context.Context, l tryLocker) error {
+	return acquireContext(ctx, l.TryRLock)
+}
+
+// acquireContext polls try with an exponential backoff until it succeeds or
+// ctx is done. sync.RWMutex cannot be waited on together with a channel, so
+// polling is the only way to give up on a held lock.
+func acquireContext(ctx context.Context, try func() bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if try() {
+		return nil
+	}
+
+	backoff := minLockBackoff
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+		if try() {
+			return nil
+		}
+		if backoff < maxLockBackoff {
+			backoff *= 2
+		}
+		timer.Reset(backoff)
+	}
+}
@@ -0,0 +1,222 @@
+package sortedmap
+
+import (
+	"reflect"
+	"unsafe"
+
+	"golang.org/x/exp/constraints"
+)
+
+// Tuples are encoded so that comparing the strings compares the items
+// lexicographically. Every item is followed by tupleSep: numbers in big
+// endian with the order fixed up, and strings with 0x00 escaped as 0x00 0xFF
+// and terminated by 0x00. Because no encoded item is a prefix of another,
+// cutting the separator of the last item gives a bound just below all tuples
+// starting with the items, and raising it gives one just above them.
+const (
+	tupleSep   = 0x01
+	tupleAbove = 0x02
+)
+
+// Tuple2 is a key of two items ordered lexicographically. It can be used as
+// the key of any container of this package in the natural order.
+type Tuple2[A constraints.Ordered, B constraints.Ordered] string
+
+// Tuple3 is a key of three items ordered lexicographically.
+type Tuple3[A constraints.Ordered, B constraints.Ordered, C constraints.Ordered] string
+
+func NewTuple2[A constraints.Ordered, B constraints.Ordered](a A, b B) Tuple2[A, B] {
+	buf := make([]byte, 0, 32)
+	buf = appendTupleItem(buf, a)
+	buf = appendTupleItem(buf, b)
+	return Tuple2[A, B](buf)
+}
+
+func NewTuple3[A constraints.Ordered, B constraints.Ordered, C constraints.Ordered](a A, b B, c C) Tuple3[A, B, C] {
+	buf := make([]byte, 0, 48)
+	buf = appendTupleItem(buf, a)
+	buf = appendTupleItem(buf, b)
+	buf = appendTupleItem(buf, c)
+	return Tuple3[A, B, C](buf)
+}
+
+// Unpack returns the items. It panics on the bounds returned by the prefix
+// and range functions, which are not tuples.
+func (t Tuple2[A, B]) Unpack() (A, B) {
+	a, rest := readTupleItem[A](string(t))
+	b, _ := readTupleItem[B](rest)
+	return a, b
+}
+
+func (t Tuple3[A, B, C]) Unpack() (A, B, C) {
+	a, rest := readTupleItem[A](string(t))
+	b, rest := readTupleItem[B](rest)
+	c, _ := readTupleItem[C](rest)
+	return a, b, c
+}
+
+// Tuple2Prefix returns the bounds for GetByInclusiveRange of the tuples
+// whose first item is a.
+func Tuple2Prefix[A constraints.Ordered, B constraints.Ordered](a A) (Tuple2[A, B], Tuple2[A, B]) {
+	start, end := prefixBounds(appendTupleItem(nil, a))
+	return Tuple2[A, B](start), Tuple2[A, B](end)
+}
+
+// Tuple2Range returns the bounds for GetByInclusiveRange of the tuples whose
+// first item is a and second item is in [from, to).
+func Tuple2Range[A constraints.Ordered, B constraints.Ordered](a A, from B, to B) (Tuple2[A, B], Tuple2[A, B]) {
+	start, end := rangeBounds(appendTupleItem(nil, a), from, to)
+	return Tuple2[A, B](start), Tuple2[A, B](end)
+}
+
+// Tuple3Prefix returns the bounds for GetByInclusiveRange of the tuples
+// whose first item is a.
+func Tuple3Prefix[A constraints.Ordered, B constraints.Ordered, C constraints.Ordered](a A) (Tuple3[A, B, C], Tuple3[A, B, C]) {
+	start, end := prefixBounds(appendTupleItem(nil, a))
+	return Tuple3[A, B, C](start), Tuple3[A, B, C](end)
+}
+
+// Tuple3Prefix2 returns the bounds for GetByInclusiveRange of the tuples
+// whose first items are a and b.
+func Tuple3Prefix2[A constraints.Ordered, B constraints.Ordered, C constraints.Ordered](a A, b B) (Tuple3[A, B, C], Tuple3[A, B, C]) {
+	start, end := prefixBounds(appendTupleItem(appendTupleItem(nil, a), b))
+	return Tuple3[A, B, C](start), Tuple3[A, B, C](end)
+}
+
+// Tuple3Range returns the bounds for GetByInclusiveRange of the tuples whose
+// first items are a and b and third item is in [from, to).
+func Tuple3Range[A constraints.Ordered, B constraints.Ordered, C constraints.Ordered](a A, b B, from C, to C) (Tuple3[A, B, C], Tuple3[A, B, C]) {
+	start, end := rangeBounds(appendTupleItem(appendTupleItem(nil, a), b), from, to)
+	return Tuple3[A, B, C](start), Tuple3[A, B, C](end)
+}
+
+func prefixBounds(prefix []byte) (string, string) {
+	end := append([]byte(nil), prefix...)
+	end[len(end)-1] = tupleAbove
+	return string(prefix), string(end)
+}
+
+// rangeBounds cuts the separators after from and to, so that start is below
+// the tuples with from and end is below the ones with to.
+func rangeBounds[T constraints.Ordered](prefix []byte, from T, to T) (string, string) {
+	start := appendOrdered(append([]byte(nil), prefix...), from)
+	end := appendOrdered(prefix, to)
+	return string(start), string(end)
+}
+
+func appendTupleItem[T constraints.Ordered](buf []byte, v T) []byte {
+	return append(appendOrdered(buf, v), tupleSep)
+}
+
+func appendOrdered[T constraints.Ordered](buf []byte, v T) []byte {
+	kind := reflect.TypeOf((*T)(nil)).Elem().Kind()
+	if kind == reflect.String {
+		s := *(*string)(unsafe.Pointer(&v))
+		for i := 0; i < len(s); i++ {
+			buf = append(buf, s[i])
+			if s[i] == 0x00 {
+				buf = append(buf, 0xFF)
+			}
+		}
+		return append(buf, 0x00)
+	}
+
+	size := unsafe.Sizeof(v)
+	sign := uint64(1) << (8*size - 1)
+	u := rawBits(unsafe.Pointer(&v), size)
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		u ^= sign
+	case reflect.Float32, reflect.Float64:
+		switch {
+		case u == sign:
+			u = sign // -0 is +0
+		case u&sign != 0:
+			u = ^u & (sign<<1 - 1)
+		default:
+			u |= sign
+		}
+	}
+	for shift := 8 * int(size-1); shift >= 0; shift -= 8 {
+		buf = append(buf, byte(u>>shift))
+	}
+	return buf
+}
+
+func readTupleItem[T constraints.Ordered](s string) (T, string) {
+	v, rest := readOrdered[T](s)
+	if len(rest) == 0 || rest[0] != tupleSep {
+		panic("sortedmap: malformed tuple")
+	}
+	return v, rest[1:]
+}
+
+func readOrdered[T constraints.Ordered](s string) (T, string) {
+	var v T
+	kind := reflect.TypeOf((*T)(nil)).Elem().Kind()
+	if kind == reflect.String {
+		buf := make([]byte, 0, len(s))
+		for i := 0; i < len(s); i++ {
+			if s[i] != 0x00 {
+				buf = append(buf, s[i])
+				continue
+			}
+			if i+1 < len(s) && s[i+1] == 0xFF {
+				buf = append(buf, 0x00)
+				i++
+				continue
+			}
+			*(*string)(unsafe.Pointer(&v)) = string(buf)
+			return v, s[i+1:]
+		}
+		panic("sortedmap: malformed tuple")
+	}
+
+	size := unsafe.Sizeof(v)
+	if uintptr(len(s)) < size {
+		panic("sortedmap: malformed tuple")
+	}
+	sign := uint64(1) << (8*size - 1)
+	var u uint64
+	for i := uintptr(0); i < size; i++ {
+		u = u<<8 | uint64(s[i])
+	}
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		u ^= sign
+	case reflect.Float32, reflect.Float64:
+		if u&sign != 0 {
+			u &^= sign
+		} else {
+			u = ^u & (sign<<1 - 1)
+		}
+	}
+	setRawBits(unsafe.Pointer(&v), size, u)
+	return v, s[size:]
+}
+
+func rawBits(p unsafe.Pointer, size uintptr) uint64 {
+	switch size {
+	case 1:
+		return uint64(*(*uint8)(p))
+	case 2:
+		return uint64(*(*uint16)(p))
+	case 4:
+		return uint64(*(*uint32)(p))
+	default:
+		return *(*uint64)(p)
+	}
+}
+
+func setRawBits(p unsafe.Pointer, size uintptr, u uint64) {
+	switch size {
+	case 1:
+		*(*uint8)(p) = uint8(u)
+	case 2:
+		*(*uint16)(p) = uint16(u)
+	case 4:
+		*(*uint32)(p) = uint32(u)
+	default:
+		*(*uint64)(p) = u
+	}
+}
@@ -0,0 +1,125 @@
+package sortedmap_test
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/sapphi-red/sortedmap"
+	"github.com/stretchr/testify/assert"
+)
+
+type tenantID string
+
+func TestTuple2_Order(t *testing.T) {
+	t.Parallel()
+
+	type item struct {
+		a string
+		b int8
+	}
+	strs := []string{"", "\x00", "\x00\x00", "\x00\x01", "\x01", "a", "a\x00", "a\x00b", "ab", "b", "\xff", "\xff\x00"}
+	var items []item
+	for _, a := range strs {
+		for _, b := range []int8{math.MinInt8, -1, 0, 1, math.MaxInt8} {
+			items = append(items, item{a, b})
+		}
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	rnd.Shuffle(len(items), func(i, j int) { items[i], items[j] = items[j], items[i] })
+	keys := make([]sortedmap.Tuple2[string, int8], len(items))
+	for i, it := range items {
+		keys[i] = sortedmap.NewTuple2(it.a, it.b)
+		a, b := keys[i].Unpack()
+		assert.Equal(t, it.a, a)
+		assert.Equal(t, it.b, b)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].a != items[j].a {
+			return items[i].a < items[j].a
+		}
+		return items[i].b < items[j].b
+	})
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	for i, it := range items {
+		a, b := keys[i].Unpack()
+		assert.Equal(t, it.a, a)
+		assert.Equal(t, it.b, b)
+	}
+}
+
+func TestTuple3_Numbers(t *testing.T) {
+	t.Parallel()
+
+	floats := []float64{math.Inf(-1), -1e300, -2.5, -math.SmallestNonzeroFloat64, 0, math.SmallestNonzeroFloat64, 1, math.Inf(1)}
+	uints := []uint16{0, 1, 255, 256, math.MaxUint16}
+	ints := []int64{math.MinInt64, -256, -1, 0, 1, 256, math.MaxInt64}
+
+	var prev sortedmap.Tuple3[float64, uint16, int64]
+	for i, f := range floats {
+		for j, u := range uints {
+			for k, n := range ints {
+				key := sortedmap.NewTuple3(f, u, n)
+				if i+j+k > 0 {
+					assert.Less(t, prev, key)
+				}
+				prev = key
+
+				a, b, c := key.Unpack()
+				assert.Equal(t, f, a)
+				assert.Equal(t, u, b)
+				assert.Equal(t, n, c)
+			}
+		}
+	}
+
+	assert.Equal(t, sortedmap.NewTuple3(0.0, uint16(0), int64(0)), sortedmap.NewTuple3(math.Copysign(0, -1), uint16(0), int64(0)))
+	assert.Panics(t, func() {
+		start, _ := sortedmap.Tuple3Prefix[float64, uint16, int64](1)
+		start.Unpack()
+	})
+}
+
+func TestTuple2_Prefix(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMap[sortedmap.Tuple2[tenantID, int64], string](0)
+	for _, tenant := range []tenantID{"a", "a\x00", "ab", "b"} {
+		for ts := int64(-2); ts <= 2; ts++ {
+			m.Insert(sortedmap.NewTuple2(tenant, ts), string(tenant)+":"+string(rune('0'+ts+2)))
+		}
+	}
+
+	assert.Equal(t, []string{"a:0", "a:1", "a:2", "a:3", "a:4"}, m.GetByInclusiveRange(sortedmap.Tuple2Prefix[tenantID, int64]("a")))
+	assert.Equal(t, []string{"a\x00:1", "a\x00:2"}, m.GetByInclusiveRange(sortedmap.Tuple2Range[tenantID]("a\x00", int64(-1), 1)))
+	assert.Equal(t, []string{"b:0", "b:1", "b:2", "b:3", "b:4"}, m.GetByInclusiveRange(sortedmap.Tuple2Range[tenantID]("b", int64(math.MinInt64), math.MaxInt64)))
+	assert.Empty(t, m.GetByInclusiveRange(sortedmap.Tuple2Range[tenantID]("ab", int64(1), 1)))
+	assert.Empty(t, m.GetByInclusiveRange(sortedmap.Tuple2Prefix[tenantID, int64]("c")))
+}
+
+func TestTuple3_Prefix(t *testing.T) {
+	t.Parallel()
+
+	set := sortedmap.NewNoLockSortedSet[sortedmap.Tuple3[int, string, int]](0)
+	for a := 0; a < 3; a++ {
+		for _, b := range []string{"x", "y"} {
+			for c := 0; c < 3; c++ {
+				set.Insert(sortedmap.NewTuple3(a, b, c))
+			}
+		}
+	}
+
+	assert.Len(t, set.GetByInclusiveRange(sortedmap.Tuple3Prefix[int, string, int](1)), 6)
+	inRange := set.GetByInclusiveRange(sortedmap.Tuple3Prefix2[int, string, int](2, "x"))
+	assert.Equal(t, []sortedmap.Tuple3[int, string, int]{
+		sortedmap.NewTuple3(2, "x", 0),
+		sortedmap.NewTuple3(2, "x", 1),
+		sortedmap.NewTuple3(2, "x", 2),
+	}, inRange)
+	assert.Equal(t, []sortedmap.Tuple3[int, string, int]{
+		sortedmap.NewTuple3(0, "y", 1),
+	}, set.GetByInclusiveRange(sortedmap.Tuple3Range(0, "y", 1, 2)))
+}
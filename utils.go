@@ -10,3 +10,12 @@ func insertAt[T any](slice []T, pos int, v T) []T {
 func deleteAt[T any](slice []T, pos int) []T {
 	return append(slice[:pos], slice[pos+1:]...)
 }
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+const maxInt = int(^uint(0) >> 1)
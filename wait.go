@@ -0,0 +1,110 @@
+package sortedmap
+
+import (
+	"context"
+
+	"golang.org/x/exp/constraints"
+)
+
+// waitFor blocks until satisfied returns true. It is checked once up front
+// and again after every insert of a key accepted by match.
+func waitFor[K constraints.Ordered, V any](ctx context.Context, l *observedLock[K, V], setHook func(func(ChangeEvent[K, V])), match func(key K) bool, satisfied func() bool) error {
+	wake := make(chan struct{}, 1)
+	unsubscribe := l.subscribe(func(e ChangeEvent[K, V]) {
+		if e.Kind != ChangeInsert || !match(e.Key) {
+			return
+		}
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}, setHook)
+	defer unsubscribe()
+
+	for {
+		if satisfied() {
+			return nil
+		}
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func inRange[K constraints.Ordered](lo K, hi K, compare func(K, K) int) func(key K) bool {
+	return func(key K) bool {
+		return compare(lo, key) <= 0 && compare(key, hi) <= 0
+	}
+}
+
+func anyKey[K constraints.Ordered](key K) bool {
+	return true
+}
+
+// WaitFor blocks until key exists or ctx is done.
+func (s *SortedMap[K, V]) WaitFor(ctx context.Context, key K) error {
+	return waitFor(ctx, &s.m, s.setHook, inRange(key, key, s.s.layout.compare), func() bool {
+		return s.Contains(key)
+	})
+}
+
+// WaitForRange blocks until a key in [lo, hi] exists or ctx is done.
+func (s *SortedMap[K, V]) WaitForRange(ctx context.Context, lo K, hi K) error {
+	return waitFor(ctx, &s.m, s.setHook, inRange(lo, hi, s.s.layout.compare), func() bool {
+		s.m.RLock()
+		defer s.m.RUnlock()
+		return s.s.layout.compare(lo, hi) <= 0 && s.s.GetIndexOfGreaterOrEqual(lo) < s.s.GetIndexOfGreater(hi)
+	})
+}
+
+func (s *SortedMap[K, V]) WaitNonEmpty(ctx context.Context) error {
+	return waitFor(ctx, &s.m, s.setHook, anyKey[K], func() bool {
+		return s.Size() > 0
+	})
+}
+
+// WaitFor blocks until key exists or ctx is done.
+func (s *SortedMapCalc[K, V]) WaitFor(ctx context.Context, key K) error {
+	return waitFor(ctx, &s.m, s.setHook, inRange(key, key, s.s.layout.compare), func() bool {
+		return s.Contains(key)
+	})
+}
+
+// WaitForRange blocks until a key in [lo, hi] exists or ctx is done.
+func (s *SortedMapCalc[K, V]) WaitForRange(ctx context.Context, lo K, hi K) error {
+	return waitFor(ctx, &s.m, s.setHook, inRange(lo, hi, s.s.layout.compare), func() bool {
+		s.m.RLock()
+		defer s.m.RUnlock()
+		return s.s.layout.compare(lo, hi) <= 0 && s.s.GetIndexOfGreaterOrEqual(lo) < s.s.GetIndexOfGreater(hi)
+	})
+}
+
+func (s *SortedMapCalc[K, V]) WaitNonEmpty(ctx context.Context) error {
+	return waitFor(ctx, &s.m, s.setHook, anyKey[K], func() bool {
+		return s.Size() > 0
+	})
+}
+
+// WaitFor blocks until value exists or ctx is done.
+func (s *SortedSet[K]) WaitFor(ctx context.Context, value K) error {
+	return waitFor(ctx, &s.m, s.setHook, inRange(value, value, s.s.layout.compare), func() bool {
+		return s.Contains(value)
+	})
+}
+
+// WaitForRange blocks until a value in [lo, hi] exists or ctx is done.
+func (s *SortedSet[K]) WaitForRange(ctx context.Context, lo K, hi K) error {
+	return waitFor(ctx, &s.m, s.setHook, inRange(lo, hi, s.s.layout.compare), func() bool {
+		s.m.RLock()
+		defer s.m.RUnlock()
+		return s.s.layout.compare(lo, hi) <= 0 && s.s.GetIndexOfGreaterOrEqual(lo) < s.s.GetIndexOfGreater(hi)
+	})
+}
+
+func (s *SortedSet[K]) WaitNonEmpty(ctx context.Context) error {
+	return waitFor(ctx, &s.m, s.setHook, anyKey[K], func() bool {
+		return s.Size() > 0
+	})
+}
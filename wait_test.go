@@ -0,0 +1,69 @@
+package sortedmap_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sapphi-red/sortedmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortedMap_WaitFor(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMap[int, string](5)
+	m.Insert(1, "1")
+	assert.NoError(t, m.WaitFor(context.Background(), 1))
+
+	done := make(chan error)
+	go func() {
+		done <- m.WaitFor(context.Background(), 3)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	m.Insert(2, "2")
+	m.Insert(3, "3")
+	assert.NoError(t, <-done)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, m.WaitFor(ctx, 4), context.DeadlineExceeded)
+}
+
+func TestSortedMapCalc_WaitForRange(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMapCalc(5, safeAtoi)
+	done := make(chan error)
+	go func() {
+		done <- m.WaitForRange(context.Background(), 10, 20)
+	}()
+	m.Insert("5")
+	m.Insert("25")
+	m.Insert("15")
+	assert.NoError(t, <-done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, m.WaitForRange(ctx, 30, 40), context.Canceled)
+	assert.NoError(t, m.WaitNonEmpty(context.Background()))
+}
+
+func TestSortedSet_WaitNonEmpty(t *testing.T) {
+	t.Parallel()
+
+	set := sortedmap.NewSortedSet[int](5)
+	done := make(chan error)
+	go func() {
+		done <- set.WaitNonEmpty(context.Background())
+	}()
+	set.Insert(1)
+	assert.NoError(t, <-done)
+
+	go func() {
+		done <- set.WaitForRange(context.Background(), 5, 7)
+	}()
+	set.InsertAll([]int{2, 8, 6})
+	assert.NoError(t, <-done)
+	assert.NoError(t, set.WaitFor(context.Background(), 8))
+}
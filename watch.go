@@ -0,0 +1,170 @@
+package sortedmap
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"golang.org/x/exp/constraints"
+)
+
+type OverflowPolicy int
+
+const (
+	// OverflowDrop discards events which do not fit in the buffer.
+	OverflowDrop OverflowPolicy = iota
+	// OverflowBlock makes the writer wait until the consumer catches up.
+	OverflowBlock
+	// OverflowClose stops the watcher with ErrWatchOverflow.
+	OverflowClose
+)
+
+var ErrWatchOverflow = errors.New("sortedmap: watch buffer overflowed")
+
+type WatchOptions struct {
+	Buffer   int
+	Overflow OverflowPolicy
+}
+
+// Watcher delivers the changes of keys in [lo, hi] on C. C is closed when the
+// context is done, Stop is called or the buffer overflows with
+// OverflowClose; Err then reports the reason.
+type Watcher[K constraints.Ordered, V any] struct {
+	C <-chan ChangeEvent[K, V]
+
+	c           chan ChangeEvent[K, V]
+	lo          K
+	hi          K
+	compare     func(K, K) int
+	overflow    OverflowPolicy
+	unsubscribe func()
+
+	m       sync.Mutex
+	senders sync.WaitGroup
+	stopped bool
+	done    chan struct{}
+	err     error
+	dropped int
+}
+
+func newWatcher[K constraints.Ordered, V any](lo K, hi K, compare func(K, K) int, opts WatchOptions) *Watcher[K, V] {
+	c := make(chan ChangeEvent[K, V], opts.Buffer)
+	return &Watcher[K, V]{
+		C:        c,
+		c:        c,
+		lo:       lo,
+		hi:       hi,
+		compare:  compare,
+		overflow: opts.Overflow,
+		done:     make(chan struct{}),
+	}
+}
+
+func (w *Watcher[K, V]) start(ctx context.Context, unsubscribe func()) {
+	w.m.Lock()
+	w.unsubscribe = unsubscribe
+	if w.stopped {
+		go unsubscribe()
+	}
+	w.m.Unlock()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			w.stop(ctx.Err())
+		case <-w.done:
+		}
+	}()
+}
+
+func (w *Watcher[K, V]) deliver(e ChangeEvent[K, V]) {
+	if w.compare(e.Key, w.lo) < 0 || w.compare(w.hi, e.Key) < 0 {
+		return
+	}
+
+	w.m.Lock()
+	defer w.m.Unlock()
+	if w.stopped {
+		return
+	}
+
+	select {
+	case w.c <- e:
+		return
+	default:
+	}
+
+	switch w.overflow {
+	case OverflowBlock:
+		// release the lock so that Stop and cancellation can interrupt
+		w.senders.Add(1)
+		w.m.Unlock()
+		select {
+		case w.c <- e:
+		case <-w.done:
+		}
+		w.senders.Done()
+		w.m.Lock()
+	case OverflowClose:
+		w.stopLocked(ErrWatchOverflow)
+	default:
+		w.dropped++
+	}
+}
+
+func (w *Watcher[K, V]) stop(err error) {
+	w.m.Lock()
+	w.stopLocked(err)
+	w.m.Unlock()
+}
+
+func (w *Watcher[K, V]) stopLocked(err error) {
+	if w.stopped {
+		return
+	}
+	w.stopped = true
+	w.err = err
+	close(w.done)
+	// unsubscribing waits for the container lock, which may be held by the
+	// writer currently delivering to this watcher
+	if w.unsubscribe != nil {
+		go w.unsubscribe()
+	}
+	go func() {
+		// a blocked sender is woken up by done; wait for it to give up
+		w.senders.Wait()
+		close(w.c)
+	}()
+}
+
+func (w *Watcher[K, V]) Stop() {
+	w.stop(nil)
+}
+
+func (w *Watcher[K, V]) Err() error {
+	w.m.Lock()
+	defer w.m.Unlock()
+	return w.err
+}
+
+// Dropped returns the number of events discarded by OverflowDrop.
+func (w *Watcher[K, V]) Dropped() int {
+	w.m.Lock()
+	defer w.m.Unlock()
+	return w.dropped
+}
+
+// Watch streams the changes of keys in [lo, hi] until ctx is done.
+func (s *SortedMap[K, V]) Watch(ctx context.Context, lo K, hi K, opts WatchOptions) *Watcher[K, V] {
+	w := newWatcher[K, V](lo, hi, s.s.layout.compare, opts)
+	w.start(ctx, s.m.subscribe(w.deliver, s.setHook))
+	return w
+}
+
+// Watch streams the changes of values in [lo, hi] until ctx is done.
+// The Value and OldValue of the events are always empty.
+func (s *SortedSet[K]) Watch(ctx context.Context, lo K, hi K, opts WatchOptions) *Watcher[K, struct{}] {
+	w := newWatcher[K, struct{}](lo, hi, s.s.layout.compare, opts)
+	w.start(ctx, s.m.subscribe(w.deliver, s.setHook))
+	return w
+}
@@ -0,0 +1,102 @@
+package sortedmap_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sapphi-red/sortedmap"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortedMap_Watch(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMap[int, string](5)
+	w := m.Watch(context.Background(), 10, 20, sortedmap.WatchOptions{Buffer: 8})
+
+	m.Insert(5, "5")
+	m.Insert(10, "10")
+	m.Insert(25, "25")
+	m.CompareAndSwap(10, "10", "ten", eqString)
+	m.Delete(10)
+
+	e := <-w.C
+	assert.Equal(t, sortedmap.ChangeEvent[int, string]{Kind: sortedmap.ChangeInsert, Index: 1, Key: 10, Value: "10"}, e)
+	e = <-w.C
+	assert.Equal(t, sortedmap.ChangeEvent[int, string]{Kind: sortedmap.ChangeUpdate, Index: 1, Key: 10, Value: "ten", OldValue: "10"}, e)
+	e = <-w.C
+	assert.Equal(t, sortedmap.ChangeDelete, e.Kind)
+
+	w.Stop()
+	_, ok := <-w.C
+	assert.Equal(t, false, ok)
+	assert.NoError(t, w.Err())
+}
+
+func TestSortedMap_WatchCancel(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMap[int, string](5)
+	ctx, cancel := context.WithCancel(context.Background())
+	w := m.Watch(ctx, 0, 100, sortedmap.WatchOptions{})
+	cancel()
+
+	for range w.C {
+	}
+	assert.ErrorIs(t, w.Err(), context.Canceled)
+	m.Insert(1, "1")
+}
+
+func TestSortedMap_WatchOverflow(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMap[int, string](5)
+	drop := m.Watch(context.Background(), 0, 100, sortedmap.WatchOptions{Buffer: 1, Overflow: sortedmap.OverflowDrop})
+	closing := m.Watch(context.Background(), 0, 100, sortedmap.WatchOptions{Buffer: 1, Overflow: sortedmap.OverflowClose})
+
+	m.InsertAll([]int{1, 2, 3}, []string{"1", "2", "3"})
+
+	assert.Equal(t, 2, drop.Dropped())
+	assert.Equal(t, 1, (<-drop.C).Key)
+	drop.Stop()
+
+	assert.Equal(t, 1, (<-closing.C).Key)
+	_, ok := <-closing.C
+	assert.Equal(t, false, ok)
+	assert.ErrorIs(t, closing.Err(), sortedmap.ErrWatchOverflow)
+}
+
+func TestSortedMap_WatchBlock(t *testing.T) {
+	t.Parallel()
+
+	m := sortedmap.NewSortedMap[int, string](5)
+	w := m.Watch(context.Background(), 0, 100, sortedmap.WatchOptions{Overflow: sortedmap.OverflowBlock})
+
+	done := make(chan struct{})
+	go func() {
+		m.InsertAll([]int{1, 2, 3}, []string{"1", "2", "3"})
+		close(done)
+	}()
+	for i := 1; i <= 3; i++ {
+		assert.Equal(t, i, (<-w.C).Key)
+	}
+	<-done
+
+	go m.Insert(4, "4")
+	w.Stop()
+	for range w.C {
+	}
+}
+
+func TestSortedSet_Watch(t *testing.T) {
+	t.Parallel()
+
+	set := sortedmap.NewSortedSet[string](5)
+	w := set.Watch(context.Background(), "b", "c", sortedmap.WatchOptions{Buffer: 4})
+	set.InsertAll([]string{"a", "bb", "d"})
+	set.Delete("bb")
+
+	assert.Equal(t, sortedmap.ChangeEvent[string, struct{}]{Kind: sortedmap.ChangeInsert, Index: 1, Key: "bb"}, <-w.C)
+	assert.Equal(t, sortedmap.ChangeEvent[string, struct{}]{Kind: sortedmap.ChangeDelete, Index: 1, Key: "bb"}, <-w.C)
+	w.Stop()
+}